@@ -54,12 +54,14 @@ const sidebars = {
             "cli/modules/overview",
             "cli/modules/exec",
             "cli/modules/env",
+            "cli/modules/fs",
+            "cli/modules/http",
           ],
         },
         {
           type: "category",
           label: "CLI Commands",
-          items: ["cli/cli-commands/apic-run", "cli/cli-commands/apic-help"],
+          items: ["cli/cli-commands/apic-run", "cli/cli-commands/apic-baseline", "cli/cli-commands/apic-diff", "cli/cli-commands/apic-cache", "cli/cli-commands/apic-help"],
         },
       ],
     },
@@ -0,0 +1,225 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/1-platform/api-catalog/internal/cli/filereader"
+	"github.com/1-platform/api-catalog/internal/cli/reportmanager"
+)
+
+var asyncAPIChannelParamRegex = regexp.MustCompile(`{([^}]+)}`)
+
+const asyncAPISpecValidationRule = "asyncapi_spec_validation"
+
+var (
+	errNotAsyncAPI            = errors.New("asyncapi version field not found")
+	errAsyncAPIVersionSupport = errors.New("only asyncapi v2 schemas are supported")
+)
+
+// returns keys of a map in sorted order
+// used to keep the validation output stable
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// resolves a local json pointer ref like #/components/messages/UserSignedUp
+func resolveLocalRef(doc map[string]any, ref string) (any, error) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("only local refs are supported: %s", ref)
+	}
+
+	var current any = doc
+	for _, token := range strings.Split(ref[2:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("failed to resolve ref %s", ref)
+		}
+		if current, ok = obj[token]; !ok {
+			return nil, fmt.Errorf("failed to resolve ref %s", ref)
+		}
+	}
+
+	return current, nil
+}
+
+// collects the spec violations as findings of asyncapi_spec_validation
+type asyncAPIValidator struct {
+	doc      map[string]any
+	findings []reportmanager.ReportDef
+	checked  int
+}
+
+// pointer is the json pointer of the element
+// channel operations gets channel as path and operation as method like the rules
+func (v *asyncAPIValidator) check(pointer string, message string) bool {
+	v.checked++
+	if message == "" {
+		return true
+	}
+	finding := reportmanager.ReportDef{Message: message, Pointer: pointer}
+	if tokens := strings.Split(pointer, "/"); len(tokens) > 2 && tokens[1] == "channels" {
+		finding.Path = strings.ReplaceAll(strings.ReplaceAll(tokens[2], "~1", "/"), "~0", "~")
+		if len(tokens) > 3 && (tokens[3] == "publish" || tokens[3] == "subscribe") {
+			finding.Method = tokens[3]
+		}
+	}
+	v.findings = append(v.findings, finding)
+	return false
+}
+
+// message can be a ref, an inline message or oneOf list of messages
+// refs are replaced with the resolved message so rules get the full message
+// visited contains the refs being resolved to detect circular refs
+func (v *asyncAPIValidator) resolveMessage(message any, pointer string, visited []string) any {
+	msg, ok := message.(map[string]any)
+	if !ok {
+		v.check(pointer, "message must be an object")
+		return message
+	}
+
+	if ref, ok := msg["$ref"].(string); ok {
+		for _, r := range visited {
+			if r == ref {
+				v.check(pointer, fmt.Sprintf("circular message ref %s", strings.Join(append(visited, ref), " -> ")))
+				return message
+			}
+		}
+		resolved, err := resolveLocalRef(v.doc, ref)
+		if err != nil {
+			v.check(pointer, err.Error())
+			return message
+		}
+		return v.resolveMessage(resolved, pointer, append(visited, ref))
+	}
+
+	if oneOf, ok := msg["oneOf"].([]any); ok {
+		for i, m := range oneOf {
+			oneOf[i] = v.resolveMessage(m, fmt.Sprintf("%s/oneOf/%d", pointer, i), visited)
+		}
+		return msg
+	}
+
+	v.check(pointer, "")
+	return msg
+}
+
+// checks the document against AsyncAPI v2 structure
+// returns the violations and the number of elements checked
+// REF: https://www.asyncapi.com/docs/reference/specification/v2.6.0
+func validateAsyncAPIv2(doc map[string]any) ([]reportmanager.ReportDef, int) {
+	v := &asyncAPIValidator{doc: doc}
+
+	info, ok := doc["info"].(map[string]any)
+	if !ok {
+		v.check("/info", "field is required")
+	} else {
+		for _, field := range []string{"title", "version"} {
+			if _, ok := info[field].(string); !ok {
+				v.check("/info/"+field, "field is required")
+			} else {
+				v.check("/info/"+field, "")
+			}
+		}
+	}
+
+	if servers, ok := doc["servers"].(map[string]any); ok {
+		for _, name := range sortedKeys(servers) {
+			pointer := "/servers/" + filereader.EscapePointerToken(name)
+			server, ok := servers[name].(map[string]any)
+			if !ok {
+				v.check(pointer, "server must be an object")
+				continue
+			}
+			for _, field := range []string{"url", "protocol"} {
+				if _, ok := server[field].(string); !ok {
+					v.check(pointer+"/"+field, "field is required")
+				} else {
+					v.check(pointer+"/"+field, "")
+				}
+			}
+		}
+	}
+
+	channels, ok := doc["channels"].(map[string]any)
+	if !ok {
+		v.check("/channels", "field is required")
+		return v.findings, v.checked
+	}
+
+	operationIds := make(map[string]string)
+	for _, name := range sortedKeys(channels) {
+		pointer := "/channels/" + filereader.EscapePointerToken(name)
+		channel, ok := channels[name].(map[string]any)
+		if !ok {
+			v.check(pointer, "channel must be an object")
+			continue
+		}
+
+		// every {param} in channel name must be described in parameters
+		params, _ := channel["parameters"].(map[string]any)
+		for _, match := range asyncAPIChannelParamRegex.FindAllStringSubmatch(name, -1) {
+			if _, ok := params[match[1]]; !ok {
+				v.check(pointer, fmt.Sprintf("parameter %s is not defined", match[1]))
+			} else {
+				v.check(pointer, "")
+			}
+		}
+
+		for _, op := range []string{"publish", "subscribe"} {
+			val, ok := channel[op]
+			if !ok {
+				continue
+			}
+			opPointer := pointer + "/" + op
+			operation, ok := val.(map[string]any)
+			if !ok {
+				v.check(opPointer, "operation must be an object")
+				continue
+			}
+
+			if id, ok := operation["operationId"].(string); ok {
+				if prev, ok := operationIds[id]; ok {
+					v.check(opPointer, fmt.Sprintf("operationId %s is already used in %s", id, prev))
+				} else {
+					operationIds[id] = opPointer
+					v.check(opPointer, "")
+				}
+			}
+
+			if message, ok := operation["message"]; ok {
+				operation["message"] = v.resolveMessage(message, opPointer+"/message", nil)
+			}
+		}
+	}
+
+	return v.findings, v.checked
+}
+
+// ValidateAsyncAPI detects the asyncapi version and validates the schema
+// channel message refs are resolved in apiSchemaFile so that rules get the complete message
+// returns the spec violations and the number of elements checked
+func ValidateAsyncAPI(apiSchemaFile map[string]any, logger *CliLogger) ([]reportmanager.ReportDef, int, error) {
+	version, ok := apiSchemaFile["asyncapi"].(string)
+	if !ok {
+		return nil, 0, errNotAsyncAPI
+	}
+
+	if !strings.HasPrefix(version, "2.") {
+		return nil, 0, fmt.Errorf("%w: found %s", errAsyncAPIVersionSupport, version)
+	}
+	logger.Info(fmt.Sprintf("Detected AsyncAPI v%s schema", version))
+
+	logger.Info("Validating by AsyncAPI schema specs")
+	violations, checked := validateAsyncAPIv2(apiSchemaFile)
+	return violations, checked, nil
+}
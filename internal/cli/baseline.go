@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/1-platform/api-catalog/internal/cli/filereader"
+	"github.com/1-platform/api-catalog/internal/cli/reportmanager"
+	"github.com/spf13/cobra"
+)
+
+// a known report that will not be reported again
+type baselineEntry struct {
+	Fingerprint string `json:"fingerprint" toml:"fingerprint"`
+	Rule        string `json:"rule" toml:"rule"`
+	Severity    string `json:"severity,omitempty" toml:"severity,omitempty"`
+	Method      string `json:"method,omitempty" toml:"method,omitempty"`
+	Path        string `json:"path,omitempty" toml:"path,omitempty"`
+	Pointer     string `json:"pointer,omitempty" toml:"pointer,omitempty"`
+	Message     string `json:"message" toml:"message"`
+}
+
+type baselineFile struct {
+	Entries []baselineEntry `json:"entries" toml:"entries"`
+}
+
+// baseline details in report export
+type baselineResult struct {
+	File string `json:"file" toml:"file"`
+	// number of reports removed as they are in baseline
+	Suppressed int `json:"suppressed" toml:"suppressed"`
+	// entries in baseline that are not reported anymore
+	Stale []baselineEntry `json:"stale" toml:"stale"`
+}
+
+// rules report all methods of a path like "GET, POST" in random order
+func normalizeMethods(method string) string {
+	methods := strings.Split(strings.ToLower(method), ",")
+	for i := range methods {
+		methods[i] = strings.TrimSpace(methods[i])
+	}
+	sort.Strings(methods)
+	return strings.Join(methods, ",")
+}
+
+// pointer that tells apart the reports without a path, like schema findings
+// reports with a path are fingerprinted by it thus moving elements doesn't change them
+func fingerprintPointer(report reportmanager.ReportDef) string {
+	if report.Path == "" || strings.EqualFold(report.Path, "nil") {
+		return report.Pointer
+	}
+	return ""
+}
+
+// fingerprint is independent of line numbers thus stays the same when the schema is edited elsewhere
+func reportFingerprint(rule string, report reportmanager.ReportDef) string {
+	h := sha256.New()
+	for _, field := range []string{rule, report.Path, normalizeMethods(report.Method), report.Message, fingerprintPointer(report)} {
+		h.Write([]byte(field))
+		// separator thus fields can't merge into each other
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func newBaselineEntry(rule string, report reportmanager.ReportDef) baselineEntry {
+	return baselineEntry{
+		Fingerprint: reportFingerprint(rule, report),
+		Rule:        rule,
+		Severity:    report.Severity,
+		Method:      report.Method,
+		Path:        report.Path,
+		Pointer:     fingerprintPointer(report),
+		Message:     report.Message,
+	}
+}
+
+// removes the reports in baseline from report manager
+func applyBaseline(fr *filereader.FileReader, location string, rm reportmanager.ReportManager) (*baselineResult, error) {
+	var baseline baselineFile
+	if err := fr.ReadFile(location, &baseline); err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(baseline.Entries))
+	for _, entry := range baseline.Entries {
+		known[entry.Fingerprint] = false
+	}
+
+	result := &baselineResult{File: location, Stale: []baselineEntry{}}
+	result.Suppressed = rm.RemoveReports(func(rule string, report reportmanager.ReportDef) bool {
+		fingerprint := reportFingerprint(rule, report)
+		if _, ok := known[fingerprint]; ok {
+			known[fingerprint] = true
+			return true
+		}
+		return false
+	})
+
+	for _, entry := range baseline.Entries {
+		if !known[entry.Fingerprint] {
+			result.Stale = append(result.Stale, entry)
+		}
+	}
+
+	return result, nil
+}
+
+func baselineCommand(_cmd *cobra.Command, _args []string) {
+	logger := NewCliLogger()
+	res := runRules(logger)
+
+	baseline := baselineFile{Entries: []baselineEntry{}}
+	seen := make(map[string]bool)
+	for rule, r := range res.rm {
+		for _, report := range r.Reports {
+			entry := newBaselineEntry(rule, report)
+			if seen[entry.Fingerprint] {
+				continue
+			}
+			seen[entry.Fingerprint] = true
+			baseline.Entries = append(baseline.Entries, entry)
+		}
+	}
+	// stable file thus baseline diffs are readable in reviews
+	sort.Slice(baseline.Entries, func(i, j int) bool {
+		a, b := baseline.Entries[i], baseline.Entries[j]
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		return a.Message < b.Message
+	})
+
+	if err := res.fr.SaveFile(baselineOutputPath, &baseline); err != nil {
+		log.Fatal("Failed to save baseline\n", err)
+	}
+	logger.Success(fmt.Sprintf("Saved %d reports to baseline %s", len(baseline.Entries), baselineOutputPath))
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/1-platform/api-catalog/internal/cli/filereader"
+	"github.com/1-platform/api-catalog/internal/cli/reportmanager"
+)
+
+func TestReportFingerprint(t *testing.T) {
+	base := reportmanager.ReportDef{
+		Method:   "GET, POST",
+		Path:     "/pets",
+		Message:  "url is not kebabcase",
+		Severity: reportmanager.SeverityWarning,
+		Pointer:  "/paths/~1pets",
+		File:     "openapi.yaml",
+		Line:     10,
+		Column:   3,
+	}
+
+	tests := []struct {
+		name   string
+		rule   string
+		change func(r *reportmanager.ReportDef)
+		same   bool
+	}{
+		{name: "unchanged", rule: "url_case_checker", change: func(r *reportmanager.ReportDef) {}, same: true},
+		{name: "moved lines", rule: "url_case_checker", change: func(r *reportmanager.ReportDef) { r.Line, r.Column = 42, 7 }, same: true},
+		{name: "other file", rule: "url_case_checker", change: func(r *reportmanager.ReportDef) { r.File = "paths.yaml" }, same: true},
+		{name: "pointer", rule: "url_case_checker", change: func(r *reportmanager.ReportDef) { r.Pointer = "/paths/~1pets/get" }, same: true},
+		{name: "severity", rule: "url_case_checker", change: func(r *reportmanager.ReportDef) { r.Severity = reportmanager.SeverityError }, same: true},
+		{name: "method order and casing", rule: "url_case_checker", change: func(r *reportmanager.ReportDef) { r.Method = "post,get" }, same: true},
+		{name: "rule", rule: "url_length", change: func(r *reportmanager.ReportDef) {}, same: false},
+		{name: "path", rule: "url_case_checker", change: func(r *reportmanager.ReportDef) { r.Path = "/pet" }, same: false},
+		{name: "method", rule: "url_case_checker", change: func(r *reportmanager.ReportDef) { r.Method = "GET" }, same: false},
+		{name: "message", rule: "url_case_checker", change: func(r *reportmanager.ReportDef) { r.Message = "url is too long" }, same: false},
+		{name: "pointer without path", rule: "url_case_checker", change: func(r *reportmanager.ReportDef) { r.Path, r.Method = "", "" }, same: false},
+		// fields are separated thus they can't merge into each other
+		{name: "shifted fields", rule: "url_case_checker", change: func(r *reportmanager.ReportDef) { r.Path, r.Message = "/pets"+r.Message, "" }, same: false},
+	}
+
+	want := reportFingerprint("url_case_checker", base)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := base
+			tt.change(&report)
+			if got := reportFingerprint(tt.rule, report); (got == want) != tt.same {
+				t.Errorf("reportFingerprint() same = %v, want %v", got == want, tt.same)
+			}
+		})
+	}
+
+	// reports without a real path are told apart by their pointer
+	for _, path := range []string{"", "Nil"} {
+		schema := reportmanager.ReportDef{Path: path, Message: "Invalid casing for pet_name", Pointer: "/components/schemas/Pet/properties/pet_name"}
+		moved := schema
+		moved.Line = 12
+		other := schema
+		other.Pointer = "/components/schemas/Tag/properties/pet_name"
+
+		want := reportFingerprint("schema_case_checker", schema)
+		if reportFingerprint("schema_case_checker", moved) != want {
+			t.Errorf("path %q: moved report must keep its fingerprint", path)
+		}
+		if reportFingerprint("schema_case_checker", other) == want {
+			t.Errorf("path %q: report with another pointer must have another fingerprint", path)
+		}
+	}
+}
+
+func TestApplyBaseline(t *testing.T) {
+	fr, err := filereader.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	known := reportmanager.ReportDef{Path: "/pets", Method: "GET", Message: "known", Line: 3}
+	fixed := reportmanager.ReportDef{Path: "/pets", Method: "POST", Message: "fixed"}
+	baseline := baselineFile{Entries: []baselineEntry{
+		newBaselineEntry("rule_a", known),
+		newBaselineEntry("rule_a", fixed),
+	}}
+
+	for _, ext := range []string{"json", "yaml", "toml"} {
+		t.Run(ext, func(t *testing.T) {
+			location := filepath.Join(t.TempDir(), "apic-baseline."+ext)
+			if err := fr.SaveFile(location, &baseline); err != nil {
+				t.Fatal(err)
+			}
+
+			rm := reportmanager.New()
+			// same report on another line is still known
+			moved := known
+			moved.Line, moved.Method = 30, "get"
+			rm.PushReport("rule_a", moved)
+			rm.PushReport("rule_a", reportmanager.ReportDef{Path: "/pets", Method: "GET", Message: "new"})
+			rm.PushReport("rule_b", known)
+
+			result, err := applyBaseline(fr, location, rm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Suppressed != 1 {
+				t.Errorf("suppressed = %d, want 1", result.Suppressed)
+			}
+			if len(rm["rule_a"].Reports) != 1 || rm["rule_a"].Reports[0].Message != "new" {
+				t.Errorf("rule_a reports = %v, want only the new one", rm["rule_a"].Reports)
+			}
+			if len(rm["rule_b"].Reports) != 1 {
+				t.Errorf("rule_b reports = %v, known report of another rule must be kept", rm["rule_b"].Reports)
+			}
+			if len(result.Stale) != 1 || result.Stale[0].Message != "fixed" {
+				t.Errorf("stale = %v, want the fixed entry", result.Stale)
+			}
+		})
+	}
+
+	t.Run("no extension", func(t *testing.T) {
+		location := filepath.Join(t.TempDir(), "apic-baseline")
+		if err := os.WriteFile(location, []byte(`{"entries": []}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := applyBaseline(fr, location, reportmanager.New()); !errors.Is(err, filereader.ErrExtNotSupported) {
+			t.Errorf("applyBaseline() error = %v, want %v", err, filereader.ErrExtNotSupported)
+		}
+	})
+}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/1-platform/api-catalog/internal/cli/compiler"
+	"github.com/spf13/cobra"
+)
+
+// transpiled plugin code is cached here
+func apicCacheDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".apic/cache")
+}
+
+func cacheCleanCommand(_cmd *cobra.Command, _args []string) {
+	logger := NewCliLogger()
+
+	dir := apicCacheDir()
+	if dir == "" {
+		log.Fatal("Failed to find home directory")
+	}
+	if err := compiler.CleanCache(dir); err != nil {
+		log.Fatal("Failed to clean cache\n", err)
+	}
+	logger.Success(fmt.Sprintf("Removed cache %s", dir))
+}
@@ -4,7 +4,9 @@ import (
 	_ "embed"
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/1-platform/api-catalog/internal/cli/compiler/modules"
 	"github.com/1-platform/api-catalog/internal/cli/pluginmanager"
 	"github.com/spf13/cobra"
 )
@@ -13,14 +15,37 @@ type ApiCatalogConfig struct {
 	Title   string
 	Rules   map[string]pluginmanager.PluginUserOverride
 	Plugins pluginmanager.PluginConfFile
+	// what to do when schema doesn't meet the spec
+	// score - report violations and lower the score, abort - stop the run
+	SpecValidation string `mapstructure:"spec_validation"`
+	Thresholds     Thresholds
+	// rules can read files only within it using apic/fs, defaults to current directory
+	ProjectRoot string `mapstructure:"project_root"`
 }
 
+// spec validation modes
+const (
+	specValidationScore = "score"
+	specValidationAbort = "abort"
+)
+
 // cli flags
 var apiType string
 var apiSchemaURL string
 var configFilePath string
 var version string
 var exportReportPath string
+var baselinePath string
+var baselineOutputPath string
+var diffBasePath string
+var diffHeadPath string
+var sinceRef string
+var concurrency int
+var noCache bool
+var ruleTimeout time.Duration
+var runTimeout time.Duration
+var httpMode string
+var httpFixtures string
 
 func Run(apiVersion string) {
 	version = apiVersion
@@ -32,14 +57,58 @@ func Run(apiVersion string) {
 		Run:   runCommand,
 	}
 
-	runCmd.Flags().StringVarP(&apiType, "apiType", "a", "", "Your API Type. Allowed values: openapi")
-	runCmd.MarkFlagRequired("apiType")
+	var baselineCmd = &cobra.Command{
+		Use:   "baseline",
+		Short: "Save the current reports as baseline",
+		Long:  "Run the apic tests and save the reports to a baseline file. apic run --baseline reports only the ones not in baseline",
+		Run:   baselineCommand,
+	}
+
+	for _, cmd := range []*cobra.Command{runCmd, baselineCmd} {
+		cmd.Flags().StringVarP(&apiType, "apiType", "a", "", "Your API Type. Allowed values: openapi, graphql, asyncapi, grpc")
+		cmd.MarkFlagRequired("apiType")
+
+		cmd.PersistentFlags().StringVar(&apiSchemaURL, "schema", "", "URL or local file containing spec sheet")
+		cmd.MarkPersistentFlagRequired("schema")
+
+		cmd.PersistentFlags().StringVar(&configFilePath, "config", ".", "Path to apic configuration file")
+		cmd.PersistentFlags().IntVar(&concurrency, "concurrency", 0, "Number of rules to run in parallel. Defaults to number of CPUs")
+		cmd.PersistentFlags().DurationVar(&ruleTimeout, "rule-timeout", time.Minute, "Maximum time a rule can run. 0 disables the timeout")
+		cmd.PersistentFlags().DurationVar(&runTimeout, "timeout", 0, "Maximum time to run all the rules. 0 disables the timeout")
+		cmd.PersistentFlags().StringVar(&httpMode, "http-mode", modules.HTTPModeLive, "How apic/http sends requests. Allowed values: live, record, replay")
+		cmd.PersistentFlags().StringVar(&httpFixtures, "http-fixtures", "apic-fixtures", "Directory of the recorded apic/http responses")
+		cmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Transpile plugins without reading or writing the cache")
+	}
 
-	runCmd.PersistentFlags().StringVar(&apiSchemaURL, "schema", "", "URL or local file containing spec sheet")
-	runCmd.MarkPersistentFlagRequired("schema")
+	runCmd.PersistentFlags().StringVar(&exportReportPath, "export", "", "File path to export data. Format is detected from extension: json, yaml, toml, sarif, xml (junit), html")
+	runCmd.PersistentFlags().StringVar(&baselinePath, "baseline", "", "Baseline file of known reports. Only new reports are reported")
+	runCmd.PersistentFlags().StringVar(&sinceRef, "since", "", "Base schema file, url or git ref. Only reports on operations changed since it are reported")
+
+	baselineCmd.PersistentFlags().StringVarP(&baselineOutputPath, "output", "o", "apic-baseline.json", "File path to save the baseline")
+
+	var diffCmd = &cobra.Command{
+		Use:   "diff",
+		Short: "Find breaking changes between two openapi schemas",
+		Long:  "Compare base and head openapi schemas for breaking changes like removed endpoints, new required params, narrowed enums and changed response types",
+		Run:   diffCommand,
+	}
+
+	diffCmd.PersistentFlags().StringVar(&diffBasePath, "base", "", "URL or local file of the current openapi schema")
+	diffCmd.MarkPersistentFlagRequired("base")
+	diffCmd.PersistentFlags().StringVar(&diffHeadPath, "head", "", "URL or local file of the changed openapi schema")
+	diffCmd.MarkPersistentFlagRequired("head")
+	diffCmd.PersistentFlags().StringVar(&exportReportPath, "export", "", "File path to export data. Format is detected from extension: json, yaml, toml, sarif, xml (junit), html")
+
+	var cacheCmd = &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the cache of transpiled plugins",
+	}
 
-	runCmd.PersistentFlags().StringVar(&configFilePath, "config", ".", "Path to apic configuration file")
-	runCmd.PersistentFlags().StringVar(&exportReportPath, "export", "", "File path to export data")
+	cacheCmd.AddCommand(&cobra.Command{
+		Use:   "clean",
+		Short: "Remove the cache of transpiled plugins",
+		Run:   cacheCleanCommand,
+	})
 
 	rootCmd := &cobra.Command{
 		Use:     "apic",
@@ -47,6 +116,9 @@ func Run(apiVersion string) {
 		Version: version,
 	}
 	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(baselineCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(cacheCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
@@ -0,0 +1,92 @@
+package compiler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/goccy/go-json"
+)
+
+// options given to babel transform
+// part of the cache key thus changing it invalidates the cached code
+var babelOptions = map[string]any{
+	"presets": []string{"env"},
+}
+
+var (
+	babelBundleHashOnce sync.Once
+	babelBundleHash     string
+)
+
+// transpiled plugin code is cached on disk
+// thus unchanged plugins doesn't need babel on every run
+type transformCache struct {
+	// empty dir disables the cache
+	dir string
+}
+
+// key is the hash of the source, babel options and the babel bundle
+func (t *transformCache) key(rawCode string) string {
+	babelBundleHashOnce.Do(func() {
+		sum := sha256.Sum256([]byte(babelBundle))
+		babelBundleHash = hex.EncodeToString(sum[:])
+	})
+
+	opts, _ := json.Marshal(babelOptions)
+	h := sha256.New()
+	h.Write([]byte(babelBundleHash))
+	h.Write([]byte{0})
+	h.Write(opts)
+	h.Write([]byte{0})
+	h.Write([]byte(rawCode))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (t *transformCache) get(rawCode string) (string, bool) {
+	if t.dir == "" {
+		return "", false
+	}
+	code, err := os.ReadFile(filepath.Join(t.dir, t.key(rawCode)+".js"))
+	if err != nil {
+		return "", false
+	}
+	return string(code), true
+}
+
+func (t *transformCache) set(rawCode string, code string) error {
+	if t.dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(t.dir, 0o755); err != nil {
+		return err
+	}
+
+	// written to a temp file and renamed, thus parallel runs never read a partial file
+	tmp, err := os.CreateTemp(t.dir, "transform-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(code); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(t.dir, t.key(rawCode)+".js"))
+}
+
+// CleanCache removes the transpiled code cached in dir
+func CleanCache(dir string) error {
+	if err := os.RemoveAll(dir); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
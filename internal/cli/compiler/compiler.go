@@ -4,9 +4,12 @@
 package compiler
 
 import (
+	"context"
 	_ "embed"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 
@@ -26,6 +29,13 @@ var (
 
 var ErrExceptionInPluginCode = errors.New("plugin code error")
 
+// returned when the rule is stopped before it finished, like on a timeout
+var ErrRuleInterrupted = errors.New("rule interrupted")
+
+// maximum call depth of a rule, deeper recursion fails the rule
+// instead of growing the stack of the runtime till apic runs out of memory
+const maxCallStackSize = 10000
+
 type Logger interface {
 	Info(str string)
 	Warn(str string)
@@ -34,21 +44,48 @@ type Logger interface {
 }
 
 type Compiler struct {
-	babel        *Babel
+	runtime *goja.Runtime
+	// babel is loaded on the first transform that misses the cache
+	babel  *Babel
+	cache  *transformCache
+	logger Logger
+	// each runtime loads the modules with it
+	moduleConfig modules.Config
+	// runtime can't be used concurrently
+	mu sync.Mutex
+	// file -> program of plugin files required by rules, shared by every runtime
+	files   map[string]*goja.Program
+	filesMu sync.Mutex
+}
+
+// Runner executes transformed rules on its own runtime
+// a runtime is not goroutine safe thus a runner must be used by one goroutine at a time
+type Runner struct {
+	runtime      *goja.Runtime
 	ModuleLoader *modules.ModuleLoader
-	logger       Logger
 }
 
-func New(logger Logger) (*Compiler, error) {
+// NewRunner creates a runtime without babel as programs are already transformed
+func (c *Compiler) NewRunner() *Runner {
+	runtime := NewRuntime()
+	runtime.SetMaxCallStackSize(maxCallStackSize)
+	setConsole(runtime, c.logger)
+	return &Runner{runtime: runtime, ModuleLoader: modules.New(runtime, c.logger, c.moduleConfig)}
+}
+
+// cacheDir is where transpiled code is cached, empty cacheDir disables the cache
+func New(logger Logger, cacheDir string, moduleConfig modules.Config) (*Compiler, error) {
 	runtime := NewRuntime()
 	setConsole(runtime, logger)
-	moduleLoader := modules.New(runtime)
 
-	b, err := newBabel(runtime)
-	if err != nil {
-		return nil, err
+	cmp := &Compiler{
+		runtime: runtime,
+		cache:   &transformCache{dir: cacheDir},
+		logger:  logger,
+		files:   make(map[string]*goja.Program),
 	}
-	cmp := &Compiler{babel: b, ModuleLoader: moduleLoader, logger: logger}
+	moduleConfig.LoadFile = cmp.transformFile
+	cmp.moduleConfig = moduleConfig
 
 	return cmp, nil
 }
@@ -125,19 +162,22 @@ func newBabel(runtime *goja.Runtime) (*Babel, error) {
 }
 
 func (c *Compiler) Transform(rawCode string) (*goja.Program, error) {
-	// change the code to commonjs using babel
-	v, err := c.babel.transformer(c.babel.this, c.babel.runtime.ToValue(rawCode), c.babel.runtime.ToValue(map[string]interface{}{
-		"presets": []string{"env"},
-	}))
-	if err != nil {
-		return nil, err
+	code, ok := c.cache.get(rawCode)
+	if !ok {
+		var err error
+		if code, err = c.transpile(rawCode); err != nil {
+			return nil, err
+		}
+		// a failed cache write only costs a transpile on next run
+		if err := c.cache.set(rawCode, code); err != nil {
+			c.logger.Warn(fmt.Sprintf("Failed to cache transpiled plugin code: %s", err))
+		}
 	}
 
-	code := v.ToObject(c.babel.runtime).Get("code").String()
 	// wrap the commonjs module inside a function
 	// This will private scope each functions we execute
 	// Compile to a goja program thus can be executed anytime with goja
-	pgm, err := goja.Compile("test", fmt.Sprintf(`(function(exports){
+	pgm, err := goja.Compile("test", fmt.Sprintf(`(function(exports, require, module){
 		%s
 		})`, code), true)
 
@@ -148,6 +188,48 @@ func (c *Compiler) Transform(rawCode string) (*goja.Program, error) {
 	return pgm, nil
 }
 
+// plugin files are transformed once, the program is run by each runtime requiring it
+func (c *Compiler) transformFile(file string) (*goja.Program, error) {
+	c.filesMu.Lock()
+	defer c.filesMu.Unlock()
+
+	if pgm, ok := c.files[file]; ok {
+		return pgm, nil
+	}
+
+	rawCode, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	pgm, err := c.Transform(string(rawCode))
+	if err != nil {
+		return nil, err
+	}
+	c.files[file] = pgm
+	return pgm, nil
+}
+
+// change the code to commonjs using babel
+func (c *Compiler) transpile(rawCode string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.babel == nil {
+		b, err := newBabel(c.runtime)
+		if err != nil {
+			return "", err
+		}
+		c.babel = b
+	}
+
+	v, err := c.babel.transformer(c.babel.this, c.babel.runtime.ToValue(rawCode), c.babel.runtime.ToValue(babelOptions))
+	if err != nil {
+		return "", err
+	}
+
+	return v.ToObject(c.babel.runtime).Get("code").String(), nil
+}
+
 type KeyValuePairs struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
@@ -155,39 +237,93 @@ type KeyValuePairs struct {
 
 // these are the data that will be given to js code execution env
 type RunConfig struct {
-	ApiSchema map[string]interface{}               `json:"schema"`
-	Type      string                               `json:"type"`
-	SetScore  func(category string, score float32) `json:"setScore"`
-	Report    func(body *reportmanager.ReportDef)  `json:"report"`
+	ApiSchema map[string]interface{} `json:"schema"`
+	Type      string                 `json:"type"`
+	// returned errors are thrown as exception in js
+	SetScore func(category string, score float32) error `json:"setScore"`
+	Report   func(body *reportmanager.ReportDef) error  `json:"report"`
+	// json pointer in schema -> the $ref location it was resolved from
+	SchemaRefs map[string]string `json:"refs"`
+	// only available for openapi
+	Operations []Operation `json:"operations"`
+	// rule being run and the capabilities granted to it, not exposed to js
+	Rule         string   `json:"-"`
+	File         string   `json:"-"`
+	Capabilities []string `json:"-"`
 }
 
-func (c *Compiler) Run(pgm *goja.Program, cfg *RunConfig, ruleOpt map[string]any) error {
-	v, err := c.babel.runtime.RunProgram(pgm)
-	if err != nil {
-		return err
-	}
+// Run executes the rule until it finishes or ctx is done
+// rule is interrupted once ctx is done and ErrRuleInterrupted is returned
+func (r *Runner) Run(ctx context.Context, pgm *goja.Program, cfg *RunConfig, ruleOpt map[string]any) error {
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		select {
+		case <-ctx.Done():
+			r.runtime.Interrupt(ctx.Err())
+		case <-stop:
+		}
+	}()
 
-	// the wrapped function is preparing to execute
-	call, ok := goja.AssertFunction(v)
-	if !ok {
-		return fmt.Errorf("failed to get exports")
+	r.ModuleLoader.SetRule(ctx, cfg.Rule, ruleDir(cfg), cfg.Capabilities)
+	err := run(r.runtime, r.ModuleLoader, pgm, cfg, ruleOpt)
+
+	// interrupt must not leak into the next rule on the runtime
+	close(stop)
+	wg.Wait()
+	r.runtime.ClearInterrupt()
+
+	return err
+}
+
+// the rule is a module thus relative requires are resolved from its directory
+func ruleDir(cfg *RunConfig) string {
+	dir, _ := os.Getwd()
+	if cfg.File != "" {
+		if file, err := filepath.Abs(cfg.File); err == nil {
+			dir = filepath.Dir(file)
+		}
 	}
+	return dir
+}
 
-	// the argument export
-	export := c.babel.runtime.NewObject()
-	// execute the wrapper function now export contains default function
-	call(goja.Undefined(), export)
+func run(runtime *goja.Runtime, loader *modules.ModuleLoader, pgm *goja.Program, cfg *RunConfig, ruleOpt map[string]any) error {
+	// execute the wrapper function now module exports contains default function
+	module := runtime.NewObject()
+	module.Set("exports", runtime.NewObject())
+	if err := loader.RunModule(pgm, module, ruleDir(cfg)); err != nil {
+		return pluginError(err)
+	}
 
 	// execute the default function with configuration passed
-	fn := export.Get("default")
-	call, ok = goja.AssertFunction(fn)
+	// commonjs rules can export the function as module.exports
+	exports := module.Get("exports")
+	call, ok := goja.AssertFunction(exports.ToObject(runtime).Get("default"))
 	if !ok {
-		return fmt.Errorf("failed to get exports")
+		if call, ok = goja.AssertFunction(exports); !ok {
+			return fmt.Errorf("failed to get exports")
+		}
 	}
-	_, err = call(goja.Undefined(), c.babel.runtime.ToValue(cfg), c.babel.runtime.ToValue(ruleOpt))
+	_, err := call(goja.Undefined(), runtime.ToValue(cfg), runtime.ToValue(ruleOpt))
 	if err != nil {
-		return fmt.Errorf("%s%w", err.Error(), ErrExceptionInPluginCode)
+		return pluginError(err)
 	}
 
 	return nil
 }
+
+// an interrupt isn't an exception of the plugin code
+// stack overflow has no message thus the limit is told instead
+func pluginError(err error) error {
+	var interrupted *goja.InterruptedError
+	if errors.As(err, &interrupted) {
+		return ErrRuleInterrupted
+	}
+	var overflow *goja.StackOverflowError
+	if errors.As(err, &overflow) {
+		return fmt.Errorf("call stack exceeded %d calls, check for infinite recursion: %w", maxCallStackSize, ErrExceptionInPluginCode)
+	}
+	return fmt.Errorf("%s%w", err.Error(), ErrExceptionInPluginCode)
+}
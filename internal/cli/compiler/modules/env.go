@@ -7,15 +7,17 @@ import (
 )
 
 // to get and set os environment values
-// TODO(akhilmhdh): Add logger to know env manipulation
+// needs env-read and env-write capabilities
 func (m *ModuleLoader) envModule() goja.Value {
 	obj := m.runtime.NewObject()
 
 	obj.Set("__esModule", true)
 	obj.Set("setEnv", func(envName string, envValue string) {
+		m.use(CapabilityEnvWrite, envName)
 		os.Setenv(envName, envValue)
 	})
 	obj.Set("getEnv", func(envName string) string {
+		m.use(CapabilityEnvRead, envName)
 		return os.Getenv(envName)
 	})
 
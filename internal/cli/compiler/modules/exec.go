@@ -2,33 +2,93 @@ package modules
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"os"
 	"os/exec"
+	"strings"
+	"time"
 
 	"github.com/dop251/goja"
 )
 
 type ExecCommandRun struct {
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	ExitCode int    `json:"exitCode"`
+	// kept for rules written before stdout and stderr were split
+	// data is the stdout, error is the failure to run or the stderr
 	Data  string `json:"data,omitempty"`
 	Error string `json:"error,omitempty"`
 }
 
+type ExecCommandOptions struct {
+	Args []string `json:"args"`
+	Cwd  string   `json:"cwd"`
+	// added to the environment of the apic process
+	Env       map[string]string `json:"env"`
+	Stdin     string            `json:"stdin"`
+	TimeoutMs int               `json:"timeoutMs"`
+}
+
 // Module to execute system commands
+// needs exec capability
 func (m *ModuleLoader) execCommandModule() goja.Value {
 	obj := m.runtime.NewObject()
 	// to allow default export
 	obj.Set("__esModule", true)
-	obj.Set("default", func(command string) *ExecCommandRun {
-		cmd := exec.Command(command)
-		var outb, errb bytes.Buffer
+	obj.Set("default", func(command string, opts *ExecCommandOptions) *ExecCommandRun {
+		if opts == nil {
+			opts = &ExecCommandOptions{}
+		}
+		m.use(CapabilityExec, strings.Join(append([]string{command}, opts.Args...), " "))
+
+		// command is killed when the rule is interrupted too
+		ctx := m.ctx
+		if opts.TimeoutMs > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, time.Duration(opts.TimeoutMs)*time.Millisecond)
+			defer cancel()
+		}
 
+		cmd := exec.CommandContext(ctx, command, opts.Args...)
+		cmd.Dir = opts.Cwd
+		if len(opts.Env) > 0 {
+			cmd.Env = os.Environ()
+			for k, v := range opts.Env {
+				cmd.Env = append(cmd.Env, k+"="+v)
+			}
+		}
+		if opts.Stdin != "" {
+			cmd.Stdin = strings.NewReader(opts.Stdin)
+		}
+
+		var outb, errb bytes.Buffer
 		cmd.Stdout = &outb
 		cmd.Stderr = &errb
 
-		if err := cmd.Run(); err != nil {
-			return &ExecCommandRun{Data: outb.String(), Error: err.Error()}
+		res := &ExecCommandRun{}
+		err := cmd.Run()
+		res.Stdout, res.Stderr, res.Data = outb.String(), errb.String(), outb.String()
+
+		var exitErr *exec.ExitError
+		switch {
+		case ctx.Err() != nil:
+			// killed processes doesn't have an exit code
+			res.ExitCode = -1
+			res.Error = "command timed out"
+		case errors.As(err, &exitErr):
+			res.ExitCode = exitErr.ExitCode()
+			res.Error = err.Error()
+		case err != nil:
+			// command couldn't be started like not found
+			res.ExitCode = -1
+			res.Error = err.Error()
+		default:
+			res.Error = res.Stderr
 		}
 
-		return &ExecCommandRun{Data: outb.String(), Error: errb.String()}
+		return res
 	})
 
 	return obj
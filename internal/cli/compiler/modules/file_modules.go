@@ -0,0 +1,179 @@
+package modules
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dop251/goja"
+	"github.com/goccy/go-json"
+)
+
+// resolves and executes modules of plugin files like ./utils.js or node_modules packages
+// a module is executed once per runtime, later requires get it from the cache
+
+var errOutsidePlugin = errors.New("module is outside the plugin directory and project root")
+
+// resolve a relative or package module required from dir into a file
+// modules are confined to roots, symlinks are followed to not escape them
+func resolveModule(dir string, module string, roots []string) (string, error) {
+	if strings.HasPrefix(module, "./") || strings.HasPrefix(module, "../") || filepath.IsAbs(module) {
+		if !filepath.IsAbs(module) {
+			module = filepath.Join(dir, module)
+		}
+		module = filepath.Clean(module)
+		if !isWithinAny(roots, module) {
+			return "", fmt.Errorf("%w: %s", errOutsidePlugin, module)
+		}
+		if file, ok := resolveFile(module); ok {
+			// package.json main can point anywhere
+			if !isWithinAny(roots, file) {
+				return "", fmt.Errorf("%w: %s", errOutsidePlugin, file)
+			}
+			return file, nil
+		}
+		return "", fmt.Errorf("%w: %s", errUnknownModule, module)
+	}
+
+	// packages are looked up in node_modules of dir and its parents within roots
+	for current := filepath.Clean(dir); isWithinAny(roots, current); current = filepath.Dir(current) {
+		if file, ok := resolveFile(filepath.Join(current, "node_modules", module)); ok {
+			if !isWithinAny(roots, file) {
+				return "", fmt.Errorf("%w: %s", errOutsidePlugin, file)
+			}
+			return file, nil
+		}
+		if filepath.Dir(current) == current {
+			break
+		}
+	}
+	return "", fmt.Errorf("%w: %s", errUnknownModule, module)
+}
+
+func isWithinAny(roots []string, p string) bool {
+	for _, root := range roots {
+		if root != "" && isWithinResolved(root, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// file as it is, with .js extension or as a directory with package.json main or index.js
+func resolveFile(location string) (string, bool) {
+	if info, err := os.Stat(location); err == nil && !info.IsDir() {
+		return location, true
+	}
+	if info, err := os.Stat(location + ".js"); err == nil && !info.IsDir() {
+		return location + ".js", true
+	}
+
+	if raw, err := os.ReadFile(filepath.Join(location, "package.json")); err == nil {
+		var pkg struct {
+			Main string `json:"main"`
+		}
+		if err := json.Unmarshal(raw, &pkg); err == nil && pkg.Main != "" {
+			if file, ok := resolveFile(filepath.Join(location, pkg.Main)); ok {
+				return file, true
+			}
+		}
+	}
+	if info, err := os.Stat(filepath.Join(location, "index.js")); err == nil && !info.IsDir() {
+		return filepath.Join(location, "index.js"), true
+	}
+
+	return "", false
+}
+
+// Require returns the require function for the code of a file in dir
+func (m *ModuleLoader) Require(dir string) func(module string) goja.Value {
+	return func(module string) goja.Value {
+		if strings.HasPrefix(module, "apic/") {
+			// load builtIn modules
+			if val, ok := m.builtInModules[module]; ok {
+				return val
+			}
+			panic(m.runtime.NewGoError(fmt.Errorf("%w: %s", errUnknownModule, module)))
+		}
+
+		// a rule can require files of its plugin and the project
+		file, err := resolveModule(dir, module, []string{m.ruleDir, m.config.Root})
+		if err != nil {
+			panic(m.runtime.NewGoError(err))
+		}
+		return m.loadFileModule(file)
+	}
+}
+
+func (m *ModuleLoader) loadFileModule(file string) goja.Value {
+	// json files are data read by the rule thus checked even when cached
+	isJSON := strings.EqualFold(filepath.Ext(file), ".json")
+	if isJSON {
+		m.use(CapabilityFS, file)
+	}
+
+	if mod, ok := m.fileModules[file]; ok {
+		return mod.Get("exports")
+	}
+
+	// json files are required as data
+	if isJSON {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			panic(m.runtime.NewGoError(err))
+		}
+		var data any
+		if err := m.config.Parser.ParseFile(raw, &data, "json"); err != nil {
+			panic(m.runtime.NewGoError(fmt.Errorf("failed to parse %s: %w", file, err)))
+		}
+		mod := m.runtime.NewObject()
+		mod.Set("exports", data)
+		m.fileModules[file] = mod
+		return mod.Get("exports")
+	}
+
+	if m.config.LoadFile == nil {
+		panic(m.runtime.NewGoError(fmt.Errorf("%w: %s", errUnknownModule, file)))
+	}
+	pgm, err := m.config.LoadFile(file)
+	if err != nil {
+		panic(m.runtime.NewGoError(fmt.Errorf("failed to load %s: %w", file, err)))
+	}
+
+	mod := m.runtime.NewObject()
+	mod.Set("exports", m.runtime.NewObject())
+	// cached before running thus circular requires get the partial exports like in node
+	m.fileModules[file] = mod
+	if err := m.RunModule(pgm, mod, filepath.Dir(file)); err != nil {
+		delete(m.fileModules, file)
+		var ex *goja.Exception
+		var interrupted *goja.InterruptedError
+		if errors.As(err, &ex) || errors.As(err, &interrupted) {
+			// rethrown as it is, thus the rule can catch it and interrupts are kept
+			panic(err)
+		}
+		panic(m.runtime.NewGoError(err))
+	}
+
+	return mod.Get("exports")
+}
+
+// RunModule executes a transformed program with module as its module object
+// requires within the program are resolved from dir
+func (m *ModuleLoader) RunModule(pgm *goja.Program, mod *goja.Object, dir string) error {
+	v, err := m.runtime.RunProgram(pgm)
+	if err != nil {
+		return err
+	}
+
+	// the wrapped function is preparing to execute
+	call, ok := goja.AssertFunction(v)
+	if !ok {
+		return fmt.Errorf("failed to get exports")
+	}
+
+	_, err = call(goja.Undefined(), mod.Get("exports"), m.runtime.ToValue(m.Require(dir)), mod)
+	return err
+}
@@ -0,0 +1,140 @@
+package modules
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, file string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// project root with a plugin dir and a dir outside of it
+// the plugin has a symlink and a package whose main points outside
+func moduleTree(t *testing.T) (string, string) {
+	t.Helper()
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	outside, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plugin := filepath.Join(root, "plugin")
+	writeFile(t, filepath.Join(root, "other.js"), "")
+	writeFile(t, filepath.Join(plugin, "rule.js"), "")
+	writeFile(t, filepath.Join(plugin, "data.json"), "{}")
+	writeFile(t, filepath.Join(plugin, "lib", "utils.js"), "")
+	writeFile(t, filepath.Join(plugin, "node_modules", "pkg", "index.js"), "")
+	writeFile(t, filepath.Join(outside, "secret.js"), "")
+	writeFile(t, filepath.Join(outside, "node_modules", "evil", "index.js"), "")
+	writeFile(t, filepath.Join(outside, "plugin", "rule.js"), "")
+
+	main, err := filepath.Rel(filepath.Join(plugin, "node_modules", "escape"), filepath.Join(outside, "secret.js"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(plugin, "node_modules", "escape", "package.json"), `{"main": "`+filepath.ToSlash(main)+`"}`)
+
+	if err := os.Symlink(outside, filepath.Join(plugin, "link")); err != nil {
+		t.Skip("symlinks are not supported: ", err)
+	}
+	return root, outside
+}
+
+func TestResolveModule(t *testing.T) {
+	root, outside := moduleTree(t)
+	plugin := filepath.Join(root, "plugin")
+
+	tests := []struct {
+		name   string
+		dir    string
+		module string
+		roots  []string
+		want   string
+		err    error
+	}{
+		{name: "relative file", dir: plugin, module: "./lib/utils", roots: []string{plugin}, want: filepath.Join(plugin, "lib", "utils.js")},
+		{name: "json file", dir: plugin, module: "./data.json", roots: []string{plugin}, want: filepath.Join(plugin, "data.json")},
+		{name: "parent within root", dir: filepath.Join(plugin, "lib"), module: "../rule", roots: []string{plugin}, want: filepath.Join(plugin, "rule.js")},
+		{name: "package", dir: filepath.Join(plugin, "lib"), module: "pkg", roots: []string{plugin}, want: filepath.Join(plugin, "node_modules", "pkg", "index.js")},
+		{name: "parent outside plugin", dir: plugin, module: "../other", roots: []string{plugin}, err: errOutsidePlugin},
+		{name: "parent within project root", dir: plugin, module: "../other", roots: []string{plugin, root}, want: filepath.Join(root, "other.js")},
+		{name: "absolute outside", dir: plugin, module: filepath.Join(outside, "secret.js"), roots: []string{plugin, root}, err: errOutsidePlugin},
+		{name: "dot dot escape", dir: plugin, module: "../../../../../../../../etc/hostname", roots: []string{plugin, root}, err: errOutsidePlugin},
+		{name: "symlink escape", dir: plugin, module: "./link/secret.js", roots: []string{plugin, root}, err: errOutsidePlugin},
+		{name: "package main escape", dir: plugin, module: "escape", roots: []string{plugin, root}, err: errOutsidePlugin},
+		{name: "node_modules above roots", dir: filepath.Join(outside, "plugin"), module: "evil", roots: []string{filepath.Join(outside, "plugin")}, err: errUnknownModule},
+		{name: "empty roots", dir: plugin, module: "./rule", roots: []string{""}, err: errOutsidePlugin},
+		{name: "missing file", dir: plugin, module: "./missing", roots: []string{plugin}, err: errUnknownModule},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveModule(tt.dir, tt.module, tt.roots)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("resolveModule() error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveModule() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveModule() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFSPath(t *testing.T) {
+	root, outside := moduleTree(t)
+	m := &ModuleLoader{config: Config{Root: root}}
+
+	tests := []struct {
+		name string
+		path string
+		want string
+		err  error
+	}{
+		{name: "relative", path: "plugin/rule.js", want: filepath.Join(root, "plugin", "rule.js")},
+		{name: "cleaned", path: "plugin/lib/../rule.js", want: filepath.Join(root, "plugin", "rule.js")},
+		{name: "absolute within root", path: filepath.Join(root, "other.js"), want: filepath.Join(root, "other.js")},
+		{name: "not existing yet", path: "plugin/new.json", want: filepath.Join(root, "plugin", "new.json")},
+		{name: "root itself", path: ".", want: root},
+		{name: "dot dot escape", path: "../etc/passwd", err: errOutsideRoot},
+		{name: "absolute outside", path: filepath.Join(outside, "secret.js"), err: errOutsideRoot},
+		{name: "prefix of root", path: root + "-other/file", err: errOutsideRoot},
+		{name: "symlink escape", path: "plugin/link/secret.js", err: errOutsideRoot},
+		{name: "symlink dir escape", path: "plugin/link", err: errOutsideRoot},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.fsPath(tt.path)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("fsPath() error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("fsPath() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("fsPath() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package modules
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dop251/goja"
+)
+
+var errOutsideRoot = errors.New("path is outside the project root")
+
+type FileParser interface {
+	// ext is the file extension like json, yaml
+	ParseFile(raw []byte, data any, ext string) error
+}
+
+// resolve the path given by a rule within the project root
+// relative paths are from the root, symlinks are followed to not escape the root
+func (m *ModuleLoader) fsPath(p string) (string, error) {
+	root := m.config.Root
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(root, p)
+	}
+	p = filepath.Clean(p)
+	if !isWithinResolved(root, p) {
+		return "", fmt.Errorf("%w: %s", errOutsideRoot, p)
+	}
+
+	return p, nil
+}
+
+// p is within root also after following the symlinks of both
+// paths not existing yet are checked as they are
+func isWithinResolved(root string, p string) bool {
+	if !isWithin(root, p) {
+		return false
+	}
+
+	resolved, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		return true
+	}
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		resolvedRoot = root
+	}
+	return isWithin(resolvedRoot, resolved)
+}
+
+func isWithin(root string, p string) bool {
+	rel, err := filepath.Rel(root, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// match a slash separated path with pattern, ** matches any number of directories
+func matchGlob(pattern []string, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchGlob(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], name[0]); !ok {
+		return false
+	}
+	return matchGlob(pattern[1:], name[1:])
+}
+
+// files matching the pattern relative to root, sorted
+func (m *ModuleLoader) glob(pattern string) ([]string, error) {
+	pattern = filepath.ToSlash(filepath.Clean(pattern))
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(pattern, "/") || pattern == ".." || strings.HasPrefix(pattern, "../") {
+		return nil, fmt.Errorf("%w: %s", errOutsideRoot, pattern)
+	}
+	segments := strings.Split(pattern, "/")
+
+	matches := []string{}
+	err := filepath.WalkDir(m.config.Root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(m.config.Root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if matchGlob(segments, strings.Split(rel, "/")) {
+			matches = append(matches, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// read only access to the files of the project
+// needs fs capability
+func (m *ModuleLoader) fsModule() goja.Value {
+	obj := m.runtime.NewObject()
+
+	obj.Set("__esModule", true)
+	obj.Set("readFile", func(name string) string {
+		m.use(CapabilityFS, "readFile "+name)
+		p, err := m.fsPath(name)
+		if err != nil {
+			panic(m.runtime.NewGoError(err))
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			panic(m.runtime.NewGoError(err))
+		}
+		return string(data)
+	})
+	obj.Set("exists", func(name string) bool {
+		m.use(CapabilityFS, "exists "+name)
+		p, err := m.fsPath(name)
+		if err != nil {
+			panic(m.runtime.NewGoError(err))
+		}
+		_, err = os.Stat(p)
+		return err == nil
+	})
+	obj.Set("glob", func(pattern string) []string {
+		m.use(CapabilityFS, "glob "+pattern)
+		matches, err := m.glob(pattern)
+		if err != nil {
+			panic(m.runtime.NewGoError(err))
+		}
+		return matches
+	})
+	// parse json, yaml or toml file by its extension
+	obj.Set("parse", func(name string) any {
+		m.use(CapabilityFS, "parse "+name)
+		p, err := m.fsPath(name)
+		if err != nil {
+			panic(m.runtime.NewGoError(err))
+		}
+		raw, err := os.ReadFile(p)
+		if err != nil {
+			panic(m.runtime.NewGoError(err))
+		}
+		var data any
+		if err := m.config.Parser.ParseFile(raw, &data, strings.TrimPrefix(filepath.Ext(p), ".")); err != nil {
+			panic(m.runtime.NewGoError(fmt.Errorf("failed to parse %s: %w", name, err)))
+		}
+		return data
+	})
+
+	return obj
+}
@@ -0,0 +1,277 @@
+package modules
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/goccy/go-json"
+)
+
+// modes of apic/http
+const (
+	// requests are sent to the service
+	HTTPModeLive = "live"
+	// requests are sent to the service and responses are saved as fixtures
+	HTTPModeRecord = "record"
+	// responses are read from the fixtures, nothing is sent
+	HTTPModeReplay = "replay"
+)
+
+var errNoFixture = errors.New("no recorded response")
+
+type HTTPRequestOptions struct {
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	Body      string            `json:"body"`
+	TimeoutMs int               `json:"timeoutMs"`
+}
+
+type HTTPResponse struct {
+	Status int `json:"status"`
+	// multiple values of a header are joined with comma
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+// recorded request and its response
+// secrets are redacted thus fixtures can be committed, see redactFixture
+type httpFixture struct {
+	Method   string       `json:"method"`
+	URL      string       `json:"url"`
+	Body     string       `json:"body,omitempty"`
+	Response HTTPResponse `json:"response"`
+}
+
+// records and replays responses in fixture files
+// headers are not part of the key as they often carry tokens
+type fixtureTransport struct {
+	base http.RoundTripper
+	dir  string
+	mode string
+}
+
+func fixtureKey(method string, u string, body string) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(u))
+	h.Write([]byte{0})
+	h.Write([]byte(body))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (t *fixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	location := filepath.Join(t.dir, fixtureKey(req.Method, req.URL.String(), string(body))+".json")
+
+	if t.mode == HTTPModeReplay {
+		raw, err := os.ReadFile(location)
+		if err != nil {
+			return nil, fmt.Errorf("%w for %s %s, record it with --http-mode record", errNoFixture, req.Method, req.URL)
+		}
+		var fixture httpFixture
+		if err := json.Unmarshal(raw, &fixture); err != nil {
+			return nil, fmt.Errorf("invalid fixture %s: %w", location, err)
+		}
+		return fixture.Response.toHTTP(req), nil
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || t.mode != HTTPModeRecord {
+		return resp, err
+	}
+
+	res, err := newHTTPResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	fixture := &httpFixture{Method: req.Method, URL: req.URL.String(), Body: string(body), Response: *res}
+	redactFixture(fixture)
+	if err := writeFixture(location, fixture); err != nil {
+		return nil, err
+	}
+	return res.toHTTP(req), nil
+}
+
+// value saved in fixtures in place of secrets
+const redacted = "REDACTED"
+
+// headers of a response that carry credentials
+var redactedHeaders = []string{"authorization", "proxy-authorization", "cookie", "set-cookie"}
+
+// removes the credentials in url and headers of a fixture before it's saved
+// the fixture key is made of the full url thus replay still finds it
+func redactFixture(fixture *httpFixture) {
+	if u, err := url.Parse(fixture.URL); err == nil {
+		if u.User != nil {
+			u.User = url.User(redacted)
+		}
+		query := u.Query()
+		for k := range query {
+			query[k] = []string{redacted}
+		}
+		u.RawQuery = query.Encode()
+		fixture.URL = u.String()
+	} else {
+		fixture.URL = redacted
+	}
+
+	headers := make(map[string]string, len(fixture.Response.Headers))
+	for k, v := range fixture.Response.Headers {
+		headers[k] = v
+	}
+	for _, k := range redactedHeaders {
+		if _, ok := headers[k]; ok {
+			headers[k] = redacted
+		}
+	}
+	fixture.Response.Headers = headers
+}
+
+// written to a temp file and renamed, thus parallel rules never read a partial file
+func writeFixture(location string, fixture *httpFixture) error {
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(location), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(location), "fixture-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), location)
+}
+
+func newHTTPResponse(resp *http.Response) (*HTTPResponse, error) {
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := make(map[string]string, len(resp.Header))
+	for k, v := range resp.Header {
+		headers[strings.ToLower(k)] = strings.Join(v, ", ")
+	}
+	return &HTTPResponse{Status: resp.StatusCode, Headers: headers, Body: string(body)}, nil
+}
+
+func (r *HTTPResponse) toHTTP(req *http.Request) *http.Response {
+	header := make(http.Header, len(r.Headers))
+	for k, v := range r.Headers {
+		header.Set(k, v)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+		StatusCode:    r.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+func (m *ModuleLoader) httpClient() *http.Client {
+	base := http.DefaultTransport
+	if m.config.HTTPClient != nil && m.config.HTTPClient.Transport != nil {
+		base = m.config.HTTPClient.Transport
+	}
+	if m.config.HTTPMode == HTTPModeRecord || m.config.HTTPMode == HTTPModeReplay {
+		base = &fixtureTransport{base: base, dir: m.config.HTTPFixtures, mode: m.config.HTTPMode}
+	}
+	return &http.Client{Transport: base}
+}
+
+// Module to send http requests to a running service
+// needs net capability
+func (m *ModuleLoader) httpModule() goja.Value {
+	obj := m.runtime.NewObject()
+	client := m.httpClient()
+
+	request := func(location string, opts *HTTPRequestOptions) *HTTPResponse {
+		if opts == nil {
+			opts = &HTTPRequestOptions{}
+		}
+		method := strings.ToUpper(opts.Method)
+		if method == "" {
+			method = http.MethodGet
+		}
+		m.use(CapabilityNet, method+" "+location)
+
+		// the client of file reader can read local files too
+		u, err := url.Parse(location)
+		if err != nil {
+			panic(m.runtime.NewGoError(err))
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			panic(m.runtime.NewGoError(fmt.Errorf("unsupported url scheme %q, only http and https are allowed", u.Scheme)))
+		}
+
+		// request is cancelled when the rule is interrupted too
+		ctx := m.ctx
+		if opts.TimeoutMs > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, time.Duration(opts.TimeoutMs)*time.Millisecond)
+			defer cancel()
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, u.String(), strings.NewReader(opts.Body))
+		if err != nil {
+			panic(m.runtime.NewGoError(err))
+		}
+		for k, v := range opts.Headers {
+			req.Header.Set(k, v)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			panic(m.runtime.NewGoError(err))
+		}
+		res, err := newHTTPResponse(resp)
+		if err != nil {
+			panic(m.runtime.NewGoError(err))
+		}
+		return res
+	}
+
+	obj.Set("__esModule", true)
+	obj.Set("request", request)
+	obj.Set("default", request)
+
+	return obj
+}
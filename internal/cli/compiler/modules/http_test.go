@@ -0,0 +1,111 @@
+package modules
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// round tripper of an injected client, counts the requests sent through it
+type countingTransport struct {
+	base  http.RoundTripper
+	count int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.count++
+	return c.base.RoundTrip(req)
+}
+
+func TestHTTPRecordReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret-session"})
+		w.Header().Set("Authorization", "Bearer secret-token")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"method":"` + r.Method + `","body":"` + string(body) + `"}`))
+	}))
+	defer server.Close()
+
+	fixtures := t.TempDir()
+	transport := &countingTransport{base: server.Client().Transport}
+	location := strings.Replace(server.URL, "://", "://user:secret-password@", 1) + "/pets?api_key=secret-key"
+
+	send := func(mode string, method string, body string) (*http.Response, error) {
+		m := &ModuleLoader{config: Config{HTTPClient: &http.Client{Transport: transport}, HTTPMode: mode, HTTPFixtures: fixtures}}
+		req, err := http.NewRequest(method, location, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m.httpClient().Do(req)
+	}
+
+	resp, err := send(HTTPModeRecord, http.MethodPost, "rex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	// the response given to the rule is not redacted
+	if got := resp.Header.Get("Set-Cookie"); !strings.Contains(got, "secret-session") {
+		t.Errorf("recorded response Set-Cookie = %q, want the session", got)
+	}
+	if transport.count != 1 {
+		t.Errorf("requests through the injected client = %d, want 1", transport.count)
+	}
+
+	files, err := filepath.Glob(filepath.Join(fixtures, "*.json"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("fixtures = %v, want 1 file", files)
+	}
+	raw, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"secret-password", "secret-key", "secret-session", "secret-token"} {
+		if strings.Contains(string(raw), secret) {
+			t.Errorf("fixture contains %s:\n%s", secret, raw)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		want   string
+		err    error
+	}{
+		{name: "recorded", method: http.MethodPost, body: "rex", want: `{"method":"POST","body":"rex"}`},
+		{name: "other body", method: http.MethodPost, body: "tom", err: errNoFixture},
+		{name: "other method", method: http.MethodGet, err: errNoFixture},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := send(HTTPModeReplay, tt.method, tt.body)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("replay error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != http.StatusCreated || string(body) != tt.want {
+				t.Errorf("replay = %d %s, want 201 %s", resp.StatusCode, body, tt.want)
+			}
+		})
+	}
+
+	// nothing is sent in replay
+	if transport.count != 1 {
+		t.Errorf("requests through the injected client = %d, want 1", transport.count)
+	}
+}
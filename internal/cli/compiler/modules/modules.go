@@ -1,45 +1,66 @@
 package modules
 
 import (
+	"context"
 	"errors"
-	"strings"
+	"net/http"
+	"os"
 
 	"github.com/dop251/goja"
 )
 
 var errUnknownModule = errors.New("module not found")
 
+// Config is what the builtin modules need from the cli
+type Config struct {
+	// apic/fs can access only the files within root
+	Root   string
+	Parser FileParser
+	// client apic/http sends requests with, the cli gives the one of file reader
+	// defaults to http.DefaultTransport which honours HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	HTTPClient *http.Client
+	// live, record or replay. fixtures are saved in HTTPFixtures dir
+	HTTPMode     string
+	HTTPFixtures string
+	// transforms a plugin file required by a rule into a program
+	LoadFile func(file string) (*goja.Program, error)
+}
+
 type ModuleLoader struct {
 	runtime        *goja.Runtime
 	builtInModules map[string]goja.Value
+	logger         Logger
+	config         Config
+	// file -> module object of the required plugin files
+	fileModules map[string]*goja.Object
+	// rule running on the runtime and capabilities granted to it
+	ctx     context.Context
+	rule    string
+	ruleDir string
+	granted map[string]struct{}
 }
 
-func New(runtime *goja.Runtime) *ModuleLoader {
+func New(runtime *goja.Runtime, logger Logger, config Config) *ModuleLoader {
 	mod := &ModuleLoader{
 		runtime:        runtime,
 		builtInModules: make(map[string]goja.Value),
+		logger:         logger,
+		config:         config,
+		fileModules:    make(map[string]*goja.Object),
+		ctx:            context.Background(),
 	}
 	mod.loadBuiltinModules()
 
-	runtime.Set("require", mod.resolver)
+	// each module gets its own require, this one resolves from current directory
+	cwd, _ := os.Getwd()
+	runtime.Set("require", mod.Require(cwd))
 	return mod
 }
 
-func (m *ModuleLoader) resolver(module string) goja.Value {
-	if strings.HasPrefix(module, "apic/") {
-		// load builtIn modules
-		if val, ok := m.builtInModules[module]; ok {
-			return val
-		} else {
-			panic(m.runtime.NewGoError(errUnknownModule))
-		}
-	}
-
-	panic(m.runtime.NewGoError(errUnknownModule))
-}
-
 func (m *ModuleLoader) loadBuiltinModules() {
 	m.builtInModules["apic/exec"] = m.execCommandModule()
 	m.builtInModules["apic/env"] = m.envModule()
 	m.builtInModules["apic/strings"] = m.stringModule()
+	m.builtInModules["apic/fs"] = m.fsModule()
+	m.builtInModules["apic/http"] = m.httpModule()
 }
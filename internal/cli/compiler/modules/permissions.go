@@ -0,0 +1,55 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+)
+
+// capabilities a rule needs to be granted to use the builtin modules
+const (
+	CapabilityExec     = "exec"
+	CapabilityEnvRead  = "env-read"
+	CapabilityEnvWrite = "env-write"
+	CapabilityFS       = "fs"
+	CapabilityNet      = "net"
+)
+
+var Capabilities = []string{CapabilityExec, CapabilityEnvRead, CapabilityEnvWrite, CapabilityFS, CapabilityNet}
+
+func IsCapability(capability string) bool {
+	for _, c := range Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+type Logger interface {
+	Info(str string)
+	Warn(str string)
+}
+
+// SetRule sets the rule running on the runtime and the capabilities granted to it
+// modules are loaded once per runtime thus permissions are switched before each rule
+// ctx is done when the rule is interrupted, modules stop their work with it
+// dir is the directory of the rule file, files required by the rule are confined to it and the project root
+func (m *ModuleLoader) SetRule(ctx context.Context, rule string, dir string, granted []string) {
+	m.ctx = ctx
+	m.rule = rule
+	m.ruleDir = dir
+	m.granted = make(map[string]struct{}, len(granted))
+	for _, c := range granted {
+		m.granted[c] = struct{}{}
+	}
+}
+
+// every use of a capability goes through here, thus it is audit logged
+// not granted capability is thrown as error in js
+func (m *ModuleLoader) use(capability string, detail string) {
+	if _, ok := m.granted[capability]; !ok {
+		m.logger.Warn(fmt.Sprintf("audit: %s denied %s: %s", m.rule, capability, detail))
+		panic(m.runtime.NewGoError(fmt.Errorf("rule %s is not granted %s capability. Declare it in the rule config and grant it in apic.toml", m.rule, capability)))
+	}
+	m.logger.Info(fmt.Sprintf("audit: %s used %s: %s", m.rule, capability, detail))
+}
@@ -3,6 +3,7 @@ package modules
 import (
 	"errors"
 	"regexp"
+	"strings"
 
 	"github.com/dop251/goja"
 	pluralize "github.com/gertd/go-pluralize"
@@ -13,6 +14,9 @@ var camelCaseRegex = regexp.MustCompile("^[a-z]+(?:[A-Z0-9]+[a-z0-9]+[A-Za-z0-9]
 var pascalCaseRegex = regexp.MustCompile("^(?:[A-Z][a-z0-9]+)(?:[A-Z]+[a-z0-9]*)*$")
 var kebabCaseRegex = regexp.MustCompile("^[a-z0-9]+(?:-[a-z0-9]+)*$")
 
+// escapes ~ and / of a json pointer token
+var pointerTokenEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
 var errUnknownCasing = errors.New("unknown casing")
 
 func caseChecker(casing string, val string) (bool, error) {
@@ -58,5 +62,14 @@ func (m *ModuleLoader) stringModule() goja.Value {
 		return pluralize.Singular(val)
 	})
 
+	// json pointer from unescaped tokens like jsonPointer("paths", "/pets", "get")
+	obj.Set("jsonPointer", func(tokens ...string) string {
+		var pointer strings.Builder
+		for _, token := range tokens {
+			pointer.WriteString("/" + pointerTokenEscaper.Replace(token))
+		}
+		return pointer.String()
+	})
+
 	return obj
 }
@@ -0,0 +1,43 @@
+package compiler
+
+// normalized api operation given to the rules along with the raw schema
+// thus rules need not walk paths and skip non operation keys like parameters, servers
+type Operation struct {
+	Path        string                `json:"path"`
+	Method      string                `json:"method"`
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Tags        []string              `json:"tags"`
+	Deprecated  bool                  `json:"deprecated"`
+	Parameters  []OperationParameter  `json:"parameters"`
+	RequestBody *OperationRequestBody `json:"requestBody"`
+	Responses   []OperationResponse   `json:"responses"`
+	// effective security requirement, operation level overrides the global one
+	Security []map[string][]string `json:"security"`
+	// json pointer of the operation in schema, rules can give it to report
+	Pointer string `json:"pointer"`
+}
+
+type OperationParameter struct {
+	Name       string         `json:"name"`
+	In         string         `json:"in"`
+	Required   bool           `json:"required"`
+	Deprecated bool           `json:"deprecated"`
+	Schema     map[string]any `json:"schema"`
+	// json pointer of the parameter, either in the path or in the operation
+	Pointer string `json:"pointer"`
+}
+
+type OperationRequestBody struct {
+	Required bool `json:"required"`
+	// media type -> schema
+	Content map[string]map[string]any `json:"content"`
+}
+
+type OperationResponse struct {
+	StatusCode  string `json:"statusCode"`
+	Description string `json:"description"`
+	// media type -> schema
+	Content map[string]map[string]any `json:"content"`
+	Headers []string                  `json:"headers"`
+}
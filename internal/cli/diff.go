@@ -0,0 +1,348 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/1-platform/api-catalog/internal/cli/filereader"
+	"github.com/1-platform/api-catalog/internal/cli/pluginmanager"
+	"github.com/1-platform/api-catalog/internal/cli/reportmanager"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/spf13/cobra"
+)
+
+// breaking change checks of apic diff, reported as rules
+const (
+	diffRemovedEndpoint     = "removed_endpoint"
+	diffNewRequiredParam    = "new_required_param"
+	diffNarrowedEnum        = "narrowed_enum"
+	diffChangedResponseType = "changed_response_type"
+	diffRemovedResponse     = "removed_response"
+)
+
+var diffChecks = []string{diffRemovedEndpoint, diffNewRequiredParam, diffNarrowedEnum, diffChangedResponseType, diffRemovedResponse}
+
+var pathParamRegex = regexp.MustCompile(`{[^}]+}`)
+
+// /pets/{id} and /pets/{petId} are the same endpoint
+func normalizePathTemplate(path string) string {
+	return pathParamRegex.ReplaceAllString(path, "{}")
+}
+
+type openAPIDiff struct {
+	base *openapi3.T
+	head *openapi3.T
+	// reports are pushed with location in base or head schema
+	report func(rule string, inBase bool, report reportmanager.ReportDef)
+}
+
+// parameters of an operation, operation level overrides path level ones
+func effectiveParams(item *openapi3.PathItem, op *openapi3.Operation) map[string]*openapi3.Parameter {
+	params := make(map[string]*openapi3.Parameter)
+	for _, p := range append(append(openapi3.Parameters{}, item.Parameters...), op.Parameters...) {
+		if p != nil && p.Value != nil {
+			params[p.Value.In+":"+p.Value.Name] = p.Value
+		}
+	}
+	return params
+}
+
+func enumValues(enum []any) map[string]bool {
+	values := make(map[string]bool, len(enum))
+	for _, v := range enum {
+		values[fmt.Sprint(v)] = true
+	}
+	return values
+}
+
+func (d *openAPIDiff) check() {
+	headPaths := make(map[string]string, len(d.head.Paths))
+	for path := range d.head.Paths {
+		headPaths[normalizePathTemplate(path)] = path
+	}
+
+	basePaths := make([]string, 0, len(d.base.Paths))
+	for path := range d.base.Paths {
+		basePaths = append(basePaths, path)
+	}
+	sort.Strings(basePaths)
+
+	for _, path := range basePaths {
+		baseItem := d.base.Paths[path]
+		headPath, ok := headPaths[normalizePathTemplate(path)]
+		var headItem *openapi3.PathItem
+		if ok {
+			headItem = d.head.Paths[headPath]
+		}
+
+		for _, method := range []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"} {
+			baseOp := baseItem.GetOperation(strings.ToUpper(method))
+			if baseOp == nil {
+				continue
+			}
+
+			var headOp *openapi3.Operation
+			if headItem != nil {
+				headOp = headItem.GetOperation(strings.ToUpper(method))
+			}
+			if headOp == nil {
+				d.report(diffRemovedEndpoint, true, reportmanager.ReportDef{
+					Path:    path,
+					Method:  method,
+					Message: fmt.Sprintf("%s %s is removed", strings.ToUpper(method), path),
+					Pointer: openAPIPointer(path, method),
+				})
+				continue
+			}
+
+			d.checkOperation(headPath, method, baseItem, baseOp, headItem, headOp)
+		}
+	}
+}
+
+func (d *openAPIDiff) checkOperation(path, method string, baseItem *openapi3.PathItem, baseOp *openapi3.Operation, headItem *openapi3.PathItem, headOp *openapi3.Operation) {
+	pointer := openAPIPointer(path, method)
+	report := func(rule string, message string) {
+		d.report(rule, false, reportmanager.ReportDef{Path: path, Method: method, Message: message, Pointer: pointer})
+	}
+
+	baseParams := effectiveParams(baseItem, baseOp)
+	headParams := effectiveParams(headItem, headOp)
+	keys := make([]string, 0, len(headParams))
+	for key := range headParams {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		head := headParams[key]
+		base, ok := baseParams[key]
+		// path params are matched by position in path, not by name
+		if head.In == openapi3.ParameterInPath {
+			continue
+		}
+		if head.Required && (!ok || !base.Required) {
+			report(diffNewRequiredParam, fmt.Sprintf("%s parameter %s is required", head.In, head.Name))
+		}
+		if ok {
+			d.checkEnums(base.Schema, head.Schema, fmt.Sprintf("%s parameter %s", head.In, head.Name), map[schemaPair]bool{}, report)
+		}
+	}
+
+	if headOp.RequestBody != nil && headOp.RequestBody.Value != nil {
+		headBody := headOp.RequestBody.Value
+		var baseBody *openapi3.RequestBody
+		if baseOp.RequestBody != nil {
+			baseBody = baseOp.RequestBody.Value
+		}
+		if headBody.Required && (baseBody == nil || !baseBody.Required) {
+			report(diffNewRequiredParam, "request body is required")
+		}
+		if baseBody != nil {
+			for _, mediaType := range sortedContentTypes(headBody.Content) {
+				if base, ok := baseBody.Content[mediaType]; ok {
+					d.checkEnums(base.Schema, headBody.Content[mediaType].Schema, fmt.Sprintf("request body %s", mediaType), map[schemaPair]bool{}, report)
+				}
+			}
+		}
+	}
+
+	statusCodes := make([]string, 0, len(baseOp.Responses))
+	for code := range baseOp.Responses {
+		statusCodes = append(statusCodes, code)
+	}
+	sort.Strings(statusCodes)
+	for _, code := range statusCodes {
+		base, head := baseOp.Responses[code], headOp.Responses[code]
+		if base == nil || base.Value == nil {
+			continue
+		}
+		// clients handling the status code like 200 break when it is replaced with 201
+		if head == nil {
+			report(diffRemovedResponse, fmt.Sprintf("response %s is removed", code))
+			continue
+		}
+		if head.Value == nil {
+			continue
+		}
+		for _, mediaType := range sortedContentTypes(base.Value.Content) {
+			headContent, ok := head.Value.Content[mediaType]
+			if !ok {
+				report(diffChangedResponseType, fmt.Sprintf("response %s no longer returns %s", code, mediaType))
+				continue
+			}
+			d.checkResponseTypes(base.Value.Content[mediaType].Schema, headContent.Schema, fmt.Sprintf("response %s %s", code, mediaType), map[schemaPair]bool{}, report)
+		}
+	}
+}
+
+func sortedContentTypes(content openapi3.Content) []string {
+	types := make([]string, 0, len(content))
+	for mediaType := range content {
+		types = append(types, mediaType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// base and head schemas compared at a location
+// a base schema shared by refs can be compared with different head schemas
+type schemaPair struct {
+	base *openapi3.Schema
+	head *openapi3.Schema
+}
+
+// request values that were valid in base must be valid in head
+func (d *openAPIDiff) checkEnums(base, head *openapi3.SchemaRef, location string, visited map[schemaPair]bool, report func(string, string)) {
+	if base == nil || base.Value == nil || head == nil || head.Value == nil || visited[schemaPair{base.Value, head.Value}] {
+		return
+	}
+	visited[schemaPair{base.Value, head.Value}] = true
+
+	if len(head.Value.Enum) > 0 {
+		headValues := enumValues(head.Value.Enum)
+		if len(base.Value.Enum) == 0 {
+			report(diffNarrowedEnum, fmt.Sprintf("%s is restricted to enum", location))
+		} else {
+			var removed []string
+			for _, v := range base.Value.Enum {
+				if !headValues[fmt.Sprint(v)] {
+					removed = append(removed, fmt.Sprint(v))
+				}
+			}
+			if len(removed) > 0 {
+				report(diffNarrowedEnum, fmt.Sprintf("%s enum values removed: %s", location, strings.Join(removed, ", ")))
+			}
+		}
+	}
+
+	for _, name := range sortedSchemaProperties(base.Value.Properties) {
+		if headProp, ok := head.Value.Properties[name]; ok {
+			d.checkEnums(base.Value.Properties[name], headProp, location+"."+name, visited, report)
+		}
+	}
+	d.checkEnums(base.Value.Items, head.Value.Items, location+"[]", visited, report)
+}
+
+// response values that clients parse with base must be the same in head
+func (d *openAPIDiff) checkResponseTypes(base, head *openapi3.SchemaRef, location string, visited map[schemaPair]bool, report func(string, string)) {
+	if base == nil || base.Value == nil || head == nil || head.Value == nil || visited[schemaPair{base.Value, head.Value}] {
+		return
+	}
+	visited[schemaPair{base.Value, head.Value}] = true
+
+	if base.Value.Type != "" && head.Value.Type != "" && base.Value.Type != head.Value.Type {
+		report(diffChangedResponseType, fmt.Sprintf("%s type changed from %s to %s", location, base.Value.Type, head.Value.Type))
+		return
+	}
+
+	for _, name := range sortedSchemaProperties(base.Value.Properties) {
+		headProp, ok := head.Value.Properties[name]
+		if !ok {
+			report(diffChangedResponseType, fmt.Sprintf("%s.%s is removed", location, name))
+			continue
+		}
+		d.checkResponseTypes(base.Value.Properties[name], headProp, location+"."+name, visited, report)
+	}
+	d.checkResponseTypes(base.Value.Items, head.Value.Items, location+"[]", visited, report)
+}
+
+func sortedSchemaProperties(props openapi3.Schemas) []string {
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// reads and validates an openapi schema for diff
+func loadOpenAPIForDiff(fr *filereader.FileReader, location string, logger *CliLogger) (*openapi3.T, *filereader.SourceMap) {
+	var schemaFile map[string]any
+	raw, err := fr.ReadFileReturnRaw(location, &schemaFile)
+	if err != nil {
+		log.Fatal("Failed to read file\n", err)
+	}
+
+	doc, _, err := ValidateOpenAPI(raw, schemaFile, location, fr, logger)
+	if err != nil {
+		log.Fatal("Failed to validate openapi schema\n", err)
+	}
+	logger.Completed(fmt.Sprintf("Read and parsed %s", location))
+
+	return doc, fr.NewSourceMap(location, raw, nil)
+}
+
+func diffCommand(_cmd *cobra.Command, _args []string) {
+	logger := NewCliLogger()
+
+	fr, err := filereader.New()
+	if err != nil {
+		log.Fatal("Failed to load filereader\n", err)
+	}
+
+	base, baseMap := loadOpenAPIForDiff(fr, diffBasePath, logger)
+	head, headMap := loadOpenAPIForDiff(fr, diffHeadPath, logger)
+
+	rm := reportmanager.New()
+	d := &openAPIDiff{
+		base: base,
+		head: head,
+		report: func(rule string, inBase bool, report reportmanager.ReportDef) {
+			sourceMap := headMap
+			if inBase {
+				sourceMap = baseMap
+			}
+			if file, pos, ok := sourceMap.Resolve(report.Pointer); ok {
+				report.File, report.Line, report.Column = file, pos.Line, pos.Column
+			}
+			report.Severity = reportmanager.SeverityError
+			rm.PushReport(rule, report)
+		},
+	}
+	d.check()
+
+	// every check is a rule thus exporters show them like apic run
+	rules := make(map[string]*pluginmanager.PluginRule, len(diffChecks))
+	passed := 0
+	for _, check := range diffChecks {
+		rules[check] = &pluginmanager.PluginRule{Severity: reportmanager.SeverityError}
+		if len(rm[check].Reports) == 0 {
+			passed++
+		}
+	}
+
+	if exportReportPath != "" {
+		logger.Info(fmt.Sprintf("Exporting reports to %s", exportReportPath))
+		expData := reportExportData{
+			Metrics:    &reportRuleMetrics{TotalRules: len(diffChecks), PassedRules: passed},
+			RuleReport: &rm,
+			rules:      rules,
+			suite:      "diff",
+			title:      fmt.Sprintf("%s -> %s", diffBasePath, diffHeadPath),
+			schema:     diffHeadPath,
+		}
+		if err := fr.SaveFile(exportReportPath, &expData); err != nil {
+			log.Fatal("Failed to export report\n", err)
+		}
+	}
+
+	logger.Title("Breaking Changes")
+	breaking := 0
+	for _, check := range diffChecks {
+		for _, report := range rm[check].Reports {
+			logger.Report(check, report.Severity, report.Method, report.Path, report.Message)
+			logger.Divider()
+			breaking++
+		}
+	}
+
+	if breaking > 0 {
+		logger.Error(fmt.Sprintf("Found %d breaking changes", breaking))
+		os.Exit(exitBreakingChanges)
+	}
+	logger.Success("No breaking changes found")
+}
@@ -0,0 +1,313 @@
+package cli
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/1-platform/api-catalog/internal/cli/reportmanager"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func loadDiffSpec(t *testing.T, paths string) *openapi3.T {
+	t.Helper()
+	spec := `
+openapi: 3.0.3
+info:
+  title: pets
+  version: 1.0.0
+paths:
+` + paths
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestOpenAPIDiff(t *testing.T) {
+	const listPets = `
+  /pets:
+    get:
+      parameters:
+        - name: status
+          in: query
+          schema:
+            type: string
+            enum: [available, sold]
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  type: object
+                  properties:
+                    name:
+                      type: string
+`
+
+	tests := []struct {
+		name string
+		base string
+		head string
+		want []string
+	}{
+		{name: "no change", base: listPets, head: listPets},
+		{
+			name: "renamed path param is the same endpoint",
+			base: `
+  /pets/{id}:
+    get:
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+      responses:
+        "200": {description: ok}
+`,
+			head: `
+  /pets/{petId}:
+    get:
+      parameters:
+        - {name: petId, in: path, required: true, schema: {type: string}}
+      responses:
+        "200": {description: ok}
+`,
+		},
+		{
+			name: "removed endpoint",
+			base: listPets + `
+  /stores:
+    get:
+      responses:
+        "200": {description: ok}
+`,
+			head: listPets,
+			want: []string{diffRemovedEndpoint},
+		},
+		{
+			name: "new required query param",
+			base: listPets,
+			head: `
+  /pets:
+    get:
+      parameters:
+        - name: status
+          in: query
+          required: true
+          schema:
+            type: string
+            enum: [available, sold]
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  type: object
+                  properties:
+                    name:
+                      type: string
+`,
+			want: []string{diffNewRequiredParam},
+		},
+		{
+			name: "request body made required",
+			base: `
+  /pets:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema: {type: object}
+      responses:
+        "201": {description: created}
+`,
+			head: `
+  /pets:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {type: object}
+      responses:
+        "201": {description: created}
+`,
+			want: []string{diffNewRequiredParam},
+		},
+		{
+			name: "narrowed enum",
+			base: listPets,
+			head: `
+  /pets:
+    get:
+      parameters:
+        - name: status
+          in: query
+          schema:
+            type: string
+            enum: [available]
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  type: object
+                  properties:
+                    name:
+                      type: string
+`,
+			want: []string{diffNarrowedEnum},
+		},
+		{
+			name: "changed response type",
+			base: listPets,
+			head: `
+  /pets:
+    get:
+      parameters:
+        - name: status
+          in: query
+          schema:
+            type: string
+            enum: [available, sold]
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  type: object
+                  properties:
+                    name:
+                      type: integer
+`,
+			want: []string{diffChangedResponseType},
+		},
+		{
+			name: "removed response",
+			base: listPets,
+			head: `
+  /pets:
+    get:
+      parameters:
+        - name: status
+          in: query
+          schema:
+            type: string
+            enum: [available, sold]
+      responses:
+        "201":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  type: object
+                  properties:
+                    name:
+                      type: string
+`,
+			want: []string{diffRemovedResponse},
+		},
+		{
+			name: "shared component compared with different head schemas",
+			base: `
+  /pets:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                a_status: {$ref: "#/components/schemas/Status"}
+                b_status: {$ref: "#/components/schemas/Status"}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  a_pet: {$ref: "#/components/schemas/Pet"}
+                  b_pet: {$ref: "#/components/schemas/Pet"}
+components:
+  schemas:
+    Status: {type: string, enum: [available, sold]}
+    Pet:
+      type: object
+      properties:
+        name: {type: string}
+`,
+			head: `
+  /pets:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                a_status: {$ref: "#/components/schemas/Status"}
+                b_status: {type: string, enum: [available]}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  a_pet: {$ref: "#/components/schemas/Pet"}
+                  b_pet: {type: object, properties: {name: {type: integer}}}
+components:
+  schemas:
+    Status: {type: string, enum: [available, sold]}
+    Pet:
+      type: object
+      properties:
+        name: {type: string}
+`,
+			want: []string{diffChangedResponseType, diffNarrowedEnum},
+		},
+		{
+			name: "added response is not breaking",
+			base: listPets,
+			head: listPets + `
+        "404":
+          description: not found
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			d := &openAPIDiff{
+				base: loadDiffSpec(t, tt.base),
+				head: loadDiffSpec(t, tt.head),
+				report: func(rule string, _ bool, _ reportmanager.ReportDef) {
+					got = append(got, rule)
+				},
+			}
+			d.check()
+
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("breaking changes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -20,19 +20,48 @@ var (
 	ErrExtNotSupported = errors.New("extension not suppoerted")
 )
 
+// data that can be exported as SARIF log
+type SARIFMarshaler interface {
+	MarshalSARIF() ([]byte, error)
+}
+
+// data that can be exported as JUnit XML report
+type JUnitMarshaler interface {
+	MarshalJUnit() ([]byte, error)
+}
+
+// data that can be exported as a static HTML page
+type HTMLMarshaler interface {
+	MarshalHTML() ([]byte, error)
+}
+
 type FileReader struct {
 	reader *http.Client
+	// reads the raw bytes of a location instead of reader when set
+	read func(location string) ([]byte, error)
 }
 
 func New() (*FileReader, error) {
 	c := &http.Client{}
-	t := &http.Transport{}
+	// clone of default transport thus remote files and apic/http honour HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	t := http.DefaultTransport.(*http.Transport).Clone()
 	t.RegisterProtocol("file", http.NewFileTransport(http.Dir("/")))
 	c.Transport = t
 
 	return &FileReader{reader: c}, nil
 }
 
+// HTTPClient is the client files are read with
+func (fr *FileReader) HTTPClient() *http.Client {
+	return fr.reader
+}
+
+// WithReadFunc returns a file reader reading every location with read
+// like reading a schema and its external refs from a git ref
+func (fr *FileReader) WithReadFunc(read func(location string) ([]byte, error)) *FileReader {
+	return &FileReader{reader: fr.reader, read: read}
+}
+
 // this is taken from internal golang codebase
 // if net/url exports this function switch to that one
 func urlFromFilePath(path string) (*url.URL, error) {
@@ -88,6 +117,22 @@ func urlFromFilePath(path string) (*url.URL, error) {
 	}, nil
 }
 
+// resolves a location relative to the base file location
+// base can be either an url or a local file path like ReadIntoRawBytes accepts
+func ResolveLocation(base string, location string) string {
+	if u, err := url.ParseRequestURI(base); err == nil && u.Scheme != "" {
+		if rel, err := url.Parse(location); err == nil {
+			return u.ResolveReference(rel).String()
+		}
+	}
+
+	if u, err := url.ParseRequestURI(location); (err == nil && u.Scheme != "") || filepath.IsAbs(location) {
+		return location
+	}
+
+	return filepath.Join(filepath.Dir(base), location)
+}
+
 func (fr *FileReader) ParseFile(raw []byte, data any, ext string) error {
 	switch ext {
 	case "json":
@@ -114,21 +159,20 @@ func (fr *FileReader) ParseFile(raw []byte, data any, ext string) error {
 }
 
 func (fr *FileReader) ReadIntoRawBytes(location string) ([]byte, error) {
+	if fr.read != nil {
+		return fr.read(location)
+	}
+
 	// if location is not url convert to proper url with file:// format
 	// We use golang http client to get files both in system and from web
-	url, err := url.ParseRequestURI(location)
-	isValidurl := err == nil && url.Scheme != ""
-	if !isValidurl {
-		url, err = urlFromFilePath(location)
+	url, err := LocationURL(location)
+	if err != nil {
+		return nil, err
 	}
 	// change the location to the url path
 	// this also handles conversion from relative to absolute path
 	location = url.String()
 
-	if err != nil {
-		return nil, err
-	}
-
 	resp, err := fr.reader.Get(location)
 	if err != nil {
 		return nil, err
@@ -153,7 +197,12 @@ func (fr *FileReader) ReadFile(location string, data any) error {
 		return err
 	}
 
-	ext := filepath.Ext(location)[1:] // .json -> json
+	// the file is parsed by its extension
+	ext := filepath.Ext(location)
+	if ext == "" {
+		return fmt.Errorf("%w: %s has no extension", ErrExtNotSupported, location)
+	}
+	ext = ext[1:] // .json -> json
 
 	if err := fr.ParseFile(raw, data, ext); err != nil {
 		return err
@@ -212,6 +261,24 @@ func (fr *FileReader) SaveFile(location string, data any) error {
 		file, err = yaml.Marshal(data)
 	case "toml":
 		file, err = toml.Marshal(data)
+	case "sarif":
+		m, ok := data.(SARIFMarshaler)
+		if !ok {
+			return ErrExtNotSupported
+		}
+		file, err = m.MarshalSARIF()
+	case "xml":
+		m, ok := data.(JUnitMarshaler)
+		if !ok {
+			return ErrExtNotSupported
+		}
+		file, err = m.MarshalJUnit()
+	case "html":
+		m, ok := data.(HTMLMarshaler)
+		if !ok {
+			return ErrExtNotSupported
+		}
+		file, err = m.MarshalHTML()
 	default:
 		return ErrExtNotSupported
 	}
@@ -0,0 +1,74 @@
+package filereader
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// line and column of an element in the source file, both starts from 1
+type Position struct {
+	Line   int `json:"line" toml:"line"`
+	Column int `json:"column" toml:"column"`
+}
+
+// json pointer -> position of the element in source file
+type PositionMap map[string]Position
+
+func (p PositionMap) walk(node *yaml.Node, pointer string) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			p.walk(child, pointer)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, val := node.Content[i], node.Content[i+1]
+			// the key is where an editor should point to
+			childPointer := pointer + "/" + EscapePointerToken(key.Value)
+			p[childPointer] = Position{Line: key.Line, Column: key.Column}
+			p.walk(val, childPointer)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			childPointer := fmt.Sprintf("%s/%d", pointer, i)
+			p[childPointer] = Position{Line: child.Line, Column: child.Column}
+			p.walk(child, childPointer)
+		}
+	}
+}
+
+// Positions parses the json or yaml source and keeps position of every element
+func Positions(raw []byte, ext string) (PositionMap, error) {
+	// json is a subset of yaml thus yaml parser gives positions for both
+	if ext != "json" && ext != "yaml" && ext != "yml" {
+		return nil, ErrExtNotSupported
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(raw, &root); err != nil {
+		return nil, err
+	}
+
+	p := PositionMap{"": {Line: 1, Column: 1}}
+	p.walk(&root, "")
+	return p, nil
+}
+
+// Lookup gives position of the pointer
+// if the pointer is not in source the closest parent is used, but not one above floor
+// floor is a parent of the pointer like the operation or schema it belongs to
+func (p PositionMap) Lookup(pointer string, floor string) (Position, bool) {
+	for len(pointer) >= len(floor) {
+		if pos, ok := p[pointer]; ok {
+			return pos, true
+		}
+		i := strings.LastIndex(pointer, "/")
+		if i < 0 {
+			break
+		}
+		pointer = pointer[:i]
+	}
+	return Position{}, false
+}
@@ -0,0 +1,258 @@
+package filereader
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// resolves $ref in a parsed document into a fully dereferenced document
+// refs can point to the same document, relative files or urls
+type refResolver struct {
+	fr   *FileReader
+	root string
+	// refs within the root document are kept as they are
+	keepLocal bool
+	// parsed documents keyed by location thus each file is read only once
+	docs map[string]map[string]any
+	// json pointer in dereferenced document -> ref location it was resolved from
+	// refs within a shared node are recorded only where it was first resolved
+	refs map[string]string
+	// ref location -> resolved node, refs to the same location share the node
+	resolved map[string]any
+	// number of circular refs kept as they are
+	circular int
+}
+
+// escape a key to be used as json pointer token
+// REF: https://www.rfc-editor.org/rfc/rfc6901#section-3
+func EscapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// reverse of EscapePointerToken
+func UnescapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
+
+// get the node in the document for a json pointer like /components/schemas/Pet
+func lookupPointer(doc any, pointer string) (any, error) {
+	if pointer == "" || pointer == "/" {
+		return doc, nil
+	}
+
+	current := doc
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = UnescapePointerToken(token)
+		switch node := current.(type) {
+		case map[string]any:
+			val, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("%s not found", pointer)
+			}
+			current = val
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("%s not found", pointer)
+			}
+			current = node[i]
+		default:
+			return nil, fmt.Errorf("%s not found", pointer)
+		}
+	}
+
+	return current, nil
+}
+
+func (r *refResolver) loadDocument(location string) (map[string]any, error) {
+	if doc, ok := r.docs[location]; ok {
+		return doc, nil
+	}
+
+	var doc map[string]any
+	if _, err := r.fr.ReadFileReturnRaw(location, &doc); err != nil {
+		return nil, err
+	}
+	r.docs[location] = doc
+	return doc, nil
+}
+
+// node is resolved within the document at base location
+// stack contains the refs being resolved to detect circular refs
+func (r *refResolver) resolve(node any, base string, pointer string, stack []string) (any, error) {
+	switch val := node.(type) {
+	case map[string]any:
+		if ref, ok := val["$ref"].(string); ok {
+			return r.resolveRef(val, ref, base, pointer, stack)
+		}
+
+		// sorted thus the node shared by refs is first resolved at the same place in every run
+		keys := make([]string, 0, len(val))
+		for key := range val {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		out := make(map[string]any, len(val))
+		for _, key := range keys {
+			child := val[key]
+			resolved, err := r.resolve(child, base, pointer+"/"+EscapePointerToken(key), stack)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = resolved
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(val))
+		for i, child := range val {
+			resolved, err := r.resolve(child, base, fmt.Sprintf("%s/%d", pointer, i), stack)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	default:
+		return node, nil
+	}
+}
+
+func (r *refResolver) resolveRef(node map[string]any, ref string, base string, pointer string, stack []string) (any, error) {
+	file, rawFragment, _ := strings.Cut(ref, "#")
+	fragment := rawFragment
+	if unescaped, err := url.PathUnescape(fragment); err == nil {
+		fragment = unescaped
+	}
+
+	location := base
+	if file != "" {
+		location = ResolveLocation(base, file)
+	}
+
+	if r.keepLocal && location == r.root {
+		if file == "" {
+			return node, nil
+		}
+		// ref from another file back to the root document becomes a local one
+		local := make(map[string]any, len(node))
+		for k, v := range node {
+			local[k] = v
+		}
+		local["$ref"] = "#" + rawFragment
+		return local, nil
+	}
+
+	target := location + "#" + fragment
+	r.refs[pointer] = target
+
+	// circular refs are kept as it is, otherwise the document will be infinite
+	for _, s := range stack {
+		if s == target {
+			r.circular++
+			return node, nil
+		}
+	}
+
+	resolved, ok := r.resolved[target]
+	if !ok {
+		doc, err := r.loadDocument(location)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve ref %s: %w", ref, err)
+		}
+
+		value, err := lookupPointer(doc, fragment)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve ref %s: %w", ref, err)
+		}
+
+		circular := r.circular
+		if resolved, err = r.resolve(value, location, pointer, append(stack, target)); err != nil {
+			return nil, err
+		}
+		// a node with circular refs kept in depends on the refs it was resolved through
+		if r.circular == circular {
+			r.resolved[target] = resolved
+		}
+	}
+
+	// siblings of $ref like description overrides the referenced value
+	obj, ok := resolved.(map[string]any)
+	if !ok || len(node) == 1 {
+		return resolved, nil
+	}
+
+	merged := make(map[string]any, len(obj)+len(node))
+	for k, v := range obj {
+		merged[k] = v
+	}
+	for k, v := range node {
+		if k == "$ref" {
+			continue
+		}
+		val, err := r.resolve(v, base, pointer+"/"+EscapePointerToken(k), stack)
+		if err != nil {
+			return nil, err
+		}
+		merged[k] = val
+	}
+	return merged, nil
+}
+
+// Dereference resolves every $ref in the document read from location
+// returns the dereferenced document and the json pointer -> original ref location map
+func (fr *FileReader) Dereference(location string, doc map[string]any) (map[string]any, map[string]string, error) {
+	// relative refs are resolved into cleaned paths, thus root should be in the same format
+	if u, err := url.ParseRequestURI(location); err != nil || u.Scheme == "" {
+		location = filepath.Clean(location)
+	}
+
+	r := newRefResolver(fr, location, doc)
+	resolved, err := r.resolve(doc, location, "", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resolved.(map[string]any), r.refs, nil
+}
+
+// ResolveExternalRefs resolves the $ref to other files, refs within the document are kept
+// swagger 2 is converted to v3 only with local refs thus external ones are resolved before it
+func (fr *FileReader) ResolveExternalRefs(location string, doc map[string]any) (map[string]any, error) {
+	if u, err := url.ParseRequestURI(location); err != nil || u.Scheme == "" {
+		location = filepath.Clean(location)
+	}
+
+	r := newRefResolver(fr, location, doc)
+	r.keepLocal = true
+	resolved, err := r.resolve(doc, location, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolved.(map[string]any), nil
+}
+
+func newRefResolver(fr *FileReader, location string, doc map[string]any) *refResolver {
+	return &refResolver{
+		fr:       fr,
+		root:     location,
+		docs:     map[string]map[string]any{location: doc},
+		refs:     make(map[string]string),
+		resolved: make(map[string]any),
+	}
+}
+
+// LocationURL converts a location accepted by ReadIntoRawBytes to an url
+// local files are converted to file:// urls
+func LocationURL(location string) (*url.URL, error) {
+	u, err := url.ParseRequestURI(location)
+	if err == nil && u.Scheme != "" {
+		return u, nil
+	}
+	return urlFromFilePath(location)
+}
@@ -0,0 +1,175 @@
+package filereader
+
+import (
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SourceMap resolves json pointers of a dereferenced document into file and position
+// elements resolved from external refs are mapped to the file they were read from
+type SourceMap struct {
+	fr   *FileReader
+	root string
+	// json pointer in dereferenced document -> the $ref location it was resolved from
+	refs map[string]string
+	// ref location -> json pointer its nested refs are recorded under
+	// a node shared by refs to the same location is resolved only once
+	resolvedAt map[string]string
+	nested     map[string]bool
+	// location -> positions, files are parsed only when needed
+	files map[string]PositionMap
+	// reports are resolved concurrently by the rules
+	mu sync.Mutex
+}
+
+// NewSourceMap creates source map of the document at location
+// refs are the one returned by Dereference, can be nil for documents without refs
+func (fr *FileReader) NewSourceMap(location string, raw []byte, refs map[string]string) *SourceMap {
+	// must be in the same format as the dereferenced ref locations
+	if u, err := url.ParseRequestURI(location); err != nil || u.Scheme == "" {
+		location = filepath.Clean(location)
+	}
+
+	s := &SourceMap{
+		fr:         fr,
+		root:       location,
+		refs:       refs,
+		resolvedAt: make(map[string]string),
+		nested:     make(map[string]bool),
+		files:      make(map[string]PositionMap),
+	}
+	s.files[location] = s.parse(location, raw)
+
+	pointers := make([]string, 0, len(refs))
+	for pointer := range refs {
+		pointers = append(pointers, pointer)
+	}
+	sort.Strings(pointers)
+	for _, pointer := range pointers {
+		if pointer == "" {
+			continue
+		}
+		if parent, ok := s.innermostRef(parentPointer(pointer)); ok && !s.nested[parent] {
+			s.nested[parent] = true
+			if _, ok := s.resolvedAt[refs[parent]]; !ok {
+				s.resolvedAt[refs[parent]] = parent
+			}
+		}
+	}
+	return s
+}
+
+func parentPointer(pointer string) string {
+	if i := strings.LastIndex(pointer, "/"); i >= 0 {
+		return pointer[:i]
+	}
+	return ""
+}
+
+// the innermost ref containing the pointer
+func (s *SourceMap) innermostRef(pointer string) (string, bool) {
+	for prefix := pointer; ; prefix = parentPointer(prefix) {
+		if _, ok := s.refs[prefix]; ok {
+			return prefix, true
+		}
+		if prefix == "" {
+			return "", false
+		}
+	}
+}
+
+func (s *SourceMap) parse(location string, raw []byte) PositionMap {
+	ext := strings.TrimPrefix(filepath.Ext(location), ".")
+	// same default as ReadFileReturnRaw
+	if ext == "" {
+		ext = "yaml"
+	}
+
+	positions, err := Positions(raw, ext)
+	if err != nil {
+		return PositionMap{}
+	}
+	return positions
+}
+
+func (s *SourceMap) positions(location string) PositionMap {
+	if positions, ok := s.files[location]; ok {
+		return positions
+	}
+
+	raw, err := s.fr.ReadIntoRawBytes(location)
+	if err != nil {
+		s.files[location] = PositionMap{}
+	} else {
+		s.files[location] = s.parse(location, raw)
+	}
+	return s.files[location]
+}
+
+// reports are located within the element they belong to
+// like /paths/{path}/{method} or /components/schemas/{name}
+const floorDepth = 3
+
+// the parent of the pointer that lookup doesn't go above
+func pointerFloor(pointer string) string {
+	depth := 0
+	for i := 0; i < len(pointer); i++ {
+		if pointer[i] != '/' {
+			continue
+		}
+		if depth == floorDepth {
+			return pointer[:i]
+		}
+		depth++
+	}
+	return pointer
+}
+
+// Resolve gives the file and position of a json pointer in dereferenced document
+// pointers not in the source are located at their closest parent within the same operation or schema
+func (s *SourceMap) Resolve(pointer string) (string, Position, bool) {
+	if s == nil {
+		return "", Position{}, false
+	}
+
+	floor := pointerFloor(pointer)
+	// moves the pointer and floor from under prefix to under target
+	rebase := func(prefix string, target string) {
+		if len(floor) >= len(prefix) {
+			floor = target + floor[len(prefix):]
+		} else {
+			// the ref is within the element thus its target is the floor
+			floor = target
+		}
+		pointer = target + pointer[len(prefix):]
+	}
+
+	// the innermost ref containing the pointer gives the file it came from
+	location := s.root
+	for hops := 0; hops <= len(s.refs); hops++ {
+		prefix, ok := s.innermostRef(pointer)
+		if !ok {
+			break
+		}
+		target := s.refs[prefix]
+		// nested refs of a shared node are looked up where it was first resolved
+		if first, ok := s.resolvedAt[target]; ok && !s.nested[prefix] {
+			rebase(prefix, first)
+			if first != prefix {
+				continue
+			}
+		}
+		file, fragment, _ := strings.Cut(target, "#")
+		location = file
+		rebase(prefix, fragment)
+		break
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pos, ok := s.positions(location).Lookup(pointer, floor)
+	return location, pos, ok
+}
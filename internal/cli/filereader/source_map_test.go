@@ -0,0 +1,95 @@
+package filereader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const sourceMapSpec = `openapi: 3.0.3
+info:
+  title: pets
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: "./pet.yaml#/Pet"
+components:
+  schemas:
+    Tag:
+      type: string
+`
+
+const sourceMapPet = `Pet:
+  type: object
+  properties:
+    name:
+      type: string
+`
+
+func TestSourceMapResolve(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "openapi.yaml")
+	pet := filepath.Join(dir, "pet.yaml")
+	for file, content := range map[string]string{root: sourceMapSpec, pet: sourceMapPet} {
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fr, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	raw, err := fr.ReadFileReturnRaw(root, &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, refs, err := fr.Dereference(root, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sourceMap := fr.NewSourceMap(root, raw, refs)
+
+	const schema = "/paths/~1pets/get/responses/200/content/application~1json/schema"
+	tests := []struct {
+		name    string
+		pointer string
+		file    string
+		pos     Position
+		ok      bool
+	}{
+		{name: "operation", pointer: "/paths/~1pets/get", file: root, pos: Position{Line: 7, Column: 5}, ok: true},
+		{name: "missing element of operation", pointer: "/paths/~1pets/get/responses/404", file: root, pos: Position{Line: 8, Column: 7}, ok: true},
+		{name: "missing operation", pointer: "/paths/~1pets/post"},
+		{name: "missing path", pointer: "/paths/~1cats"},
+		{name: "placeholder path", pointer: "/paths/Nil/nil"},
+		{name: "external ref", pointer: schema + "/properties/name", file: pet, pos: Position{Line: 4, Column: 5}, ok: true},
+		{name: "missing element of external ref", pointer: schema + "/properties/age", file: pet, pos: Position{Line: 3, Column: 3}, ok: true},
+		{name: "component", pointer: "/components/schemas/Tag", file: root, pos: Position{Line: 17, Column: 5}, ok: true},
+		{name: "missing element of component", pointer: "/components/schemas/Tag/enum", file: root, pos: Position{Line: 17, Column: 5}, ok: true},
+		{name: "missing component", pointer: "/components/schemas/Pet/properties/name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, pos, ok := sourceMap.Resolve(tt.pointer)
+			if ok != tt.ok {
+				t.Fatalf("Resolve() ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if file != tt.file || pos != tt.pos {
+				t.Errorf("Resolve() = %s:%d:%d, want %s:%d:%d", file, pos.Line, pos.Column, tt.file, tt.pos.Line, tt.pos.Column)
+			}
+		})
+	}
+}
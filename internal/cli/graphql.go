@@ -0,0 +1,414 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// normalized graphql schema given to the rules
+// the shape is kept close to introspection result so rules can work on both SDL and introspection input
+type graphqlSchema struct {
+	QueryType        string        `json:"queryType,omitempty"`
+	MutationType     string        `json:"mutationType,omitempty"`
+	SubscriptionType string        `json:"subscriptionType,omitempty"`
+	Types            []graphqlType `json:"types"`
+}
+
+type graphqlType struct {
+	Name          string             `json:"name"`
+	Kind          string             `json:"kind"`
+	Description   string             `json:"description,omitempty"`
+	Fields        []graphqlField     `json:"fields,omitempty"`
+	Interfaces    []string           `json:"interfaces,omitempty"`
+	PossibleTypes []string           `json:"possibleTypes,omitempty"`
+	EnumValues    []graphqlEnumValue `json:"enumValues,omitempty"`
+}
+
+type graphqlField struct {
+	Name              string         `json:"name"`
+	Description       string         `json:"description,omitempty"`
+	Type              string         `json:"type"`
+	Args              []graphqlField `json:"args,omitempty"`
+	DefaultValue      string         `json:"defaultValue,omitempty"`
+	IsDeprecated      bool           `json:"isDeprecated"`
+	DeprecationReason string         `json:"deprecationReason,omitempty"`
+}
+
+type graphqlEnumValue struct {
+	Name              string `json:"name"`
+	Description       string `json:"description,omitempty"`
+	IsDeprecated      bool   `json:"isDeprecated"`
+	DeprecationReason string `json:"deprecationReason,omitempty"`
+}
+
+// introspection query result
+// REF: https://spec.graphql.org/October2021/#sec-Schema-Introspection
+type introspectionResult struct {
+	Data *struct {
+		Schema *introspectionSchema `json:"__schema"`
+	} `json:"data"`
+	Schema *introspectionSchema `json:"__schema"`
+}
+
+type introspectionSchema struct {
+	QueryType        *introspectionName  `json:"queryType"`
+	MutationType     *introspectionName  `json:"mutationType"`
+	SubscriptionType *introspectionName  `json:"subscriptionType"`
+	Types            []introspectionType `json:"types"`
+}
+
+type introspectionName struct {
+	Name string `json:"name"`
+}
+
+type introspectionType struct {
+	Kind          string                   `json:"kind"`
+	Name          string                   `json:"name"`
+	Description   string                   `json:"description"`
+	Fields        []introspectionField     `json:"fields"`
+	InputFields   []introspectionField     `json:"inputFields"`
+	Interfaces    []introspectionTypeRef   `json:"interfaces"`
+	PossibleTypes []introspectionTypeRef   `json:"possibleTypes"`
+	EnumValues    []introspectionEnumValue `json:"enumValues"`
+}
+
+type introspectionField struct {
+	Name              string               `json:"name"`
+	Description       string               `json:"description"`
+	Args              []introspectionField `json:"args"`
+	Type              introspectionTypeRef `json:"type"`
+	DefaultValue      *string              `json:"defaultValue"`
+	IsDeprecated      bool                 `json:"isDeprecated"`
+	DeprecationReason *string              `json:"deprecationReason"`
+}
+
+type introspectionEnumValue struct {
+	Name              string  `json:"name"`
+	Description       string  `json:"description"`
+	IsDeprecated      bool    `json:"isDeprecated"`
+	DeprecationReason *string `json:"deprecationReason"`
+}
+
+type introspectionTypeRef struct {
+	Kind   string                `json:"kind"`
+	Name   string                `json:"name"`
+	OfType *introspectionTypeRef `json:"ofType"`
+}
+
+var errInvalidIntrospection = errors.New("invalid graphql introspection result: __schema not found")
+
+// introspection type ref to SDL type -> [Pet!]!
+func (t *introspectionTypeRef) typeString() (string, error) {
+	switch t.Kind {
+	case "NON_NULL", "LIST":
+		// truncated introspection results miss the wrapped type
+		if t.OfType == nil {
+			return "", fmt.Errorf("invalid graphql introspection result: %s type without ofType", t.Kind)
+		}
+		inner, err := t.OfType.typeString()
+		if err != nil {
+			return "", err
+		}
+		if t.Kind == "NON_NULL" {
+			return inner + "!", nil
+		}
+		return "[" + inner + "]", nil
+	default:
+		if t.Name == "" {
+			return "", fmt.Errorf("invalid graphql introspection result: %s type without name", t.Kind)
+		}
+		return t.Name, nil
+	}
+}
+
+// introspection result is a json with __schema at the top or under data
+func isIntrospection(raw []byte) bool {
+	var probe struct {
+		Schema json.RawMessage `json:"__schema"`
+		Data   *struct {
+			Schema json.RawMessage `json:"__schema"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return false
+	}
+	return probe.Schema != nil || (probe.Data != nil && probe.Data.Schema != nil)
+}
+
+func writeDescription(sb *strings.Builder, description string, indent string) {
+	if description == "" {
+		return
+	}
+	// graphql string escapes are compatible with json strings
+	quoted, _ := json.Marshal(description)
+	sb.WriteString(fmt.Sprintf("%s%s\n", indent, quoted))
+}
+
+func writeDeprecation(sb *strings.Builder, isDeprecated bool, reason *string) {
+	if !isDeprecated {
+		return
+	}
+	if reason == nil {
+		sb.WriteString(" @deprecated")
+		return
+	}
+	quoted, _ := json.Marshal(*reason)
+	sb.WriteString(fmt.Sprintf(" @deprecated(reason: %s)", quoted))
+}
+
+func writeFields(sb *strings.Builder, fields []introspectionField) error {
+	sb.WriteString(" {\n")
+	for _, field := range fields {
+		writeDescription(sb, field.Description, "  ")
+		sb.WriteString("  " + field.Name)
+		if len(field.Args) > 0 {
+			var args []string
+			for _, arg := range field.Args {
+				argType, err := arg.Type.typeString()
+				if err != nil {
+					return err
+				}
+				a := fmt.Sprintf("%s: %s", arg.Name, argType)
+				if arg.DefaultValue != nil {
+					a += " = " + *arg.DefaultValue
+				}
+				args = append(args, a)
+			}
+			sb.WriteString("(" + strings.Join(args, ", ") + ")")
+		}
+		fieldType, err := field.Type.typeString()
+		if err != nil {
+			return err
+		}
+		sb.WriteString(": " + fieldType)
+		if field.DefaultValue != nil {
+			sb.WriteString(" = " + *field.DefaultValue)
+		}
+		writeDeprecation(sb, field.IsDeprecated, field.DeprecationReason)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("}\n")
+	return nil
+}
+
+// converts an introspection result to SDL
+// thus both introspection and SDL goes through the same gqlparser validation
+func introspectionToSDL(raw []byte) (string, error) {
+	var result introspectionResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return "", err
+	}
+
+	schema := result.Schema
+	if result.Data != nil && result.Data.Schema != nil {
+		schema = result.Data.Schema
+	}
+	if schema == nil {
+		return "", errInvalidIntrospection
+	}
+
+	var sb strings.Builder
+	sb.WriteString("schema {\n")
+	if schema.QueryType != nil {
+		sb.WriteString(fmt.Sprintf("  query: %s\n", schema.QueryType.Name))
+	}
+	if schema.MutationType != nil {
+		sb.WriteString(fmt.Sprintf("  mutation: %s\n", schema.MutationType.Name))
+	}
+	if schema.SubscriptionType != nil {
+		sb.WriteString(fmt.Sprintf("  subscription: %s\n", schema.SubscriptionType.Name))
+	}
+	sb.WriteString("}\n")
+
+	for _, t := range schema.Types {
+		// builtin types are provided by gqlparser prelude
+		if strings.HasPrefix(t.Name, "__") || isBuiltinScalar(t.Name) {
+			continue
+		}
+
+		writeDescription(&sb, t.Description, "")
+		switch t.Kind {
+		case "SCALAR":
+			sb.WriteString(fmt.Sprintf("scalar %s\n", t.Name))
+		case "OBJECT", "INTERFACE":
+			keyword := "type"
+			if t.Kind == "INTERFACE" {
+				keyword = "interface"
+			}
+			sb.WriteString(fmt.Sprintf("%s %s", keyword, t.Name))
+			if len(t.Interfaces) > 0 {
+				var names []string
+				for _, i := range t.Interfaces {
+					names = append(names, i.Name)
+				}
+				sb.WriteString(" implements " + strings.Join(names, " & "))
+			}
+			if err := writeFields(&sb, t.Fields); err != nil {
+				return "", err
+			}
+		case "INPUT_OBJECT":
+			sb.WriteString("input " + t.Name)
+			if err := writeFields(&sb, t.InputFields); err != nil {
+				return "", err
+			}
+		case "UNION":
+			var names []string
+			for _, p := range t.PossibleTypes {
+				names = append(names, p.Name)
+			}
+			sb.WriteString(fmt.Sprintf("union %s = %s\n", t.Name, strings.Join(names, " | ")))
+		case "ENUM":
+			sb.WriteString(fmt.Sprintf("enum %s {\n", t.Name))
+			for _, v := range t.EnumValues {
+				writeDescription(&sb, v.Description, "  ")
+				sb.WriteString("  " + v.Name)
+				writeDeprecation(&sb, v.IsDeprecated, v.DeprecationReason)
+				sb.WriteString("\n")
+			}
+			sb.WriteString("}\n")
+		default:
+			return "", fmt.Errorf("unknown graphql type kind %s of %s", t.Kind, t.Name)
+		}
+	}
+
+	return sb.String(), nil
+}
+
+func isBuiltinScalar(name string) bool {
+	switch name {
+	case "String", "Int", "Float", "Boolean", "ID":
+		return true
+	}
+	return false
+}
+
+func deprecation(directives ast.DirectiveList) (bool, string) {
+	d := directives.ForName("deprecated")
+	if d == nil {
+		return false, ""
+	}
+	if arg := d.Arguments.ForName("reason"); arg != nil && arg.Value != nil {
+		return true, arg.Value.Raw
+	}
+	return true, ""
+}
+
+func normalizeGraphQLFields(fields ast.FieldList) []graphqlField {
+	var out []graphqlField
+	for _, f := range fields {
+		// introspection fields like __typename are added by gqlparser
+		if strings.HasPrefix(f.Name, "__") {
+			continue
+		}
+		isDeprecated, reason := deprecation(f.Directives)
+		field := graphqlField{
+			Name:              f.Name,
+			Description:       f.Description,
+			Type:              f.Type.String(),
+			IsDeprecated:      isDeprecated,
+			DeprecationReason: reason,
+		}
+		if f.DefaultValue != nil {
+			field.DefaultValue = f.DefaultValue.String()
+		}
+		for _, a := range f.Arguments {
+			arg := graphqlField{Name: a.Name, Description: a.Description, Type: a.Type.String()}
+			if a.DefaultValue != nil {
+				arg.DefaultValue = a.DefaultValue.String()
+			}
+			field.Args = append(field.Args, arg)
+		}
+		out = append(out, field)
+	}
+	return out
+}
+
+func normalizeGraphQLSchema(schema *ast.Schema) *graphqlSchema {
+	out := &graphqlSchema{Types: []graphqlType{}}
+	if schema.Query != nil {
+		out.QueryType = schema.Query.Name
+	}
+	if schema.Mutation != nil {
+		out.MutationType = schema.Mutation.Name
+	}
+	if schema.Subscription != nil {
+		out.SubscriptionType = schema.Subscription.Name
+	}
+
+	for _, def := range schema.Types {
+		if def.BuiltIn {
+			continue
+		}
+		t := graphqlType{
+			Name:        def.Name,
+			Kind:        string(def.Kind),
+			Description: def.Description,
+			Fields:      normalizeGraphQLFields(def.Fields),
+			Interfaces:  def.Interfaces,
+		}
+		if def.Kind == ast.Union {
+			t.PossibleTypes = def.Types
+		}
+		for _, v := range def.EnumValues {
+			isDeprecated, reason := deprecation(v.Directives)
+			t.EnumValues = append(t.EnumValues, graphqlEnumValue{
+				Name:              v.Name,
+				Description:       v.Description,
+				IsDeprecated:      isDeprecated,
+				DeprecationReason: reason,
+			})
+		}
+		out.Types = append(out.Types, t)
+	}
+
+	// map iteration is random, keep the output stable for reports
+	sort.Slice(out.Types, func(i, j int) bool {
+		return out.Types[i].Name < out.Types[j].Name
+	})
+
+	return out
+}
+
+// ValidateGraphQL parses a SDL or introspection json schema
+// validates it and returns the normalized schema that will be given to the rules
+func ValidateGraphQL(raw []byte, location string, logger *CliLogger) (map[string]any, error) {
+	sdl := string(raw)
+	if isIntrospection(raw) {
+		logger.Info("Detected GraphQL introspection result")
+		var err error
+		if sdl, err = introspectionToSDL(raw); err != nil {
+			return nil, err
+		}
+	} else if filepath.Ext(location) == ".json" {
+		return nil, errInvalidIntrospection
+	} else {
+		logger.Info("Detected GraphQL SDL schema")
+	}
+
+	logger.Info("Validating by GraphQL schema specs")
+	schema, err := gqlparser.LoadSchema(&ast.Source{Name: location, Input: sdl})
+	if err != nil {
+		logger.Error("Failed to meet GraphQL spec")
+		return nil, err
+	}
+
+	// rules get a plain js object like openapi schema
+	normalized, err := json.Marshal(normalizeGraphQLSchema(schema))
+	if err != nil {
+		return nil, err
+	}
+
+	var apiSchemaFile map[string]any
+	if err := json.Unmarshal(normalized, &apiSchemaFile); err != nil {
+		return nil, err
+	}
+
+	return apiSchemaFile, nil
+}
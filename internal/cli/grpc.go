@@ -0,0 +1,341 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/1-platform/api-catalog/internal/cli/filereader"
+	"github.com/bufbuild/protocompile"
+	"github.com/goccy/go-json"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// normalized protobuf schema given to the rules
+type grpcSchema struct {
+	Files    []grpcFile    `json:"files"`
+	Services []grpcService `json:"services"`
+	Messages []grpcMessage `json:"messages"`
+	Enums    []grpcEnum    `json:"enums"`
+}
+
+type grpcFile struct {
+	Name    string   `json:"name"`
+	Package string   `json:"package,omitempty"`
+	Syntax  string   `json:"syntax"`
+	Imports []string `json:"imports,omitempty"`
+}
+
+type grpcService struct {
+	Name       string       `json:"name"`
+	FullName   string       `json:"fullName"`
+	File       string       `json:"file"`
+	Deprecated bool         `json:"deprecated"`
+	Methods    []grpcMethod `json:"methods"`
+}
+
+type grpcMethod struct {
+	Name            string `json:"name"`
+	FullName        string `json:"fullName"`
+	Path            string `json:"path"`
+	InputType       string `json:"inputType"`
+	OutputType      string `json:"outputType"`
+	ClientStreaming bool   `json:"clientStreaming"`
+	ServerStreaming bool   `json:"serverStreaming"`
+	Deprecated      bool   `json:"deprecated"`
+}
+
+type grpcMessage struct {
+	Name       string      `json:"name"`
+	FullName   string      `json:"fullName"`
+	File       string      `json:"file"`
+	Deprecated bool        `json:"deprecated"`
+	Fields     []grpcField `json:"fields"`
+}
+
+type grpcField struct {
+	Name        string `json:"name"`
+	JSONName    string `json:"jsonName"`
+	Number      int    `json:"number"`
+	Type        string `json:"type"`
+	Cardinality string `json:"cardinality"`
+	IsMap       bool   `json:"isMap"`
+	OneOf       string `json:"oneOf,omitempty"`
+	Deprecated  bool   `json:"deprecated"`
+}
+
+type grpcEnum struct {
+	Name       string          `json:"name"`
+	FullName   string          `json:"fullName"`
+	File       string          `json:"file"`
+	Deprecated bool            `json:"deprecated"`
+	Values     []grpcEnumValue `json:"values"`
+}
+
+type grpcEnumValue struct {
+	Name       string `json:"name"`
+	Number     int    `json:"number"`
+	Deprecated bool   `json:"deprecated"`
+}
+
+// file extensions of compiled FileDescriptorSet
+// protoc --descriptor_set_out and buf build -o produces these
+func isDescriptorSet(location string) bool {
+	switch filepath.Ext(location) {
+	case ".pb", ".bin", ".desc", ".protoset":
+		return true
+	}
+	return false
+}
+
+func isDeprecated(d protoreflect.Descriptor) bool {
+	type deprecatable interface{ GetDeprecated() bool }
+	if opts, ok := d.Options().(deprecatable); ok {
+		return opts.GetDeprecated()
+	}
+	return false
+}
+
+func grpcFieldType(f protoreflect.FieldDescriptor) string {
+	switch f.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return string(f.Message().FullName())
+	case protoreflect.EnumKind:
+		return string(f.Enum().FullName())
+	default:
+		return f.Kind().String()
+	}
+}
+
+func (s *grpcSchema) addEnums(file string, enums protoreflect.EnumDescriptors) {
+	for i := 0; i < enums.Len(); i++ {
+		e := enums.Get(i)
+		enum := grpcEnum{
+			Name:       string(e.Name()),
+			FullName:   string(e.FullName()),
+			File:       file,
+			Deprecated: isDeprecated(e),
+			Values:     []grpcEnumValue{},
+		}
+		for j := 0; j < e.Values().Len(); j++ {
+			v := e.Values().Get(j)
+			enum.Values = append(enum.Values, grpcEnumValue{
+				Name:       string(v.Name()),
+				Number:     int(v.Number()),
+				Deprecated: isDeprecated(v),
+			})
+		}
+		s.Enums = append(s.Enums, enum)
+	}
+}
+
+// nested messages and enums are flattened, fullName keeps the nesting
+func (s *grpcSchema) addMessages(file string, messages protoreflect.MessageDescriptors) {
+	for i := 0; i < messages.Len(); i++ {
+		m := messages.Get(i)
+		// map<k, v> are compiled into synthetic entry messages
+		if m.IsMapEntry() {
+			continue
+		}
+
+		msg := grpcMessage{
+			Name:       string(m.Name()),
+			FullName:   string(m.FullName()),
+			File:       file,
+			Deprecated: isDeprecated(m),
+			Fields:     []grpcField{},
+		}
+		for j := 0; j < m.Fields().Len(); j++ {
+			f := m.Fields().Get(j)
+			field := grpcField{
+				Name:        string(f.Name()),
+				JSONName:    f.JSONName(),
+				Number:      int(f.Number()),
+				Type:        grpcFieldType(f),
+				Cardinality: f.Cardinality().String(),
+				IsMap:       f.IsMap(),
+				Deprecated:  isDeprecated(f),
+			}
+			if oneOf := f.ContainingOneof(); oneOf != nil && !oneOf.IsSynthetic() {
+				field.OneOf = string(oneOf.Name())
+			}
+			msg.Fields = append(msg.Fields, field)
+		}
+		s.Messages = append(s.Messages, msg)
+
+		s.addMessages(file, m.Messages())
+		s.addEnums(file, m.Enums())
+	}
+}
+
+func (s *grpcSchema) addFile(fd protoreflect.FileDescriptor) {
+	file := grpcFile{
+		Name:    fd.Path(),
+		Package: string(fd.Package()),
+		Syntax:  fd.Syntax().String(),
+	}
+	for i := 0; i < fd.Imports().Len(); i++ {
+		file.Imports = append(file.Imports, fd.Imports().Get(i).Path())
+	}
+	s.Files = append(s.Files, file)
+
+	for i := 0; i < fd.Services().Len(); i++ {
+		sd := fd.Services().Get(i)
+		service := grpcService{
+			Name:       string(sd.Name()),
+			FullName:   string(sd.FullName()),
+			File:       fd.Path(),
+			Deprecated: isDeprecated(sd),
+			Methods:    []grpcMethod{},
+		}
+		for j := 0; j < sd.Methods().Len(); j++ {
+			md := sd.Methods().Get(j)
+			service.Methods = append(service.Methods, grpcMethod{
+				Name:     string(md.Name()),
+				FullName: string(md.FullName()),
+				// the http/2 path used by grpc -> /package.Service/Method
+				Path:            fmt.Sprintf("/%s/%s", sd.FullName(), md.Name()),
+				InputType:       string(md.Input().FullName()),
+				OutputType:      string(md.Output().FullName()),
+				ClientStreaming: md.IsStreamingClient(),
+				ServerStreaming: md.IsStreamingServer(),
+				Deprecated:      isDeprecated(md),
+			})
+		}
+		s.Services = append(s.Services, service)
+	}
+
+	s.addMessages(fd.Path(), fd.Messages())
+	s.addEnums(fd.Path(), fd.Enums())
+}
+
+// compiles a .proto file, imports are resolved relative to the file location
+func compileProtoFile(fr *filereader.FileReader, location string) ([]protoreflect.FileDescriptor, error) {
+	cmp := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: func(path string) (io.ReadCloser, error) {
+				raw, err := fr.ReadIntoRawBytes(filereader.ResolveLocation(location, path))
+				if err != nil {
+					return nil, err
+				}
+				return io.NopCloser(bytes.NewReader(raw)), nil
+			},
+		}),
+	}
+
+	files, err := cmp.Compile(context.Background(), filepath.Base(location))
+	if err != nil {
+		return nil, err
+	}
+
+	// imported local files are part of the schema like in a descriptor set
+	var fds []protoreflect.FileDescriptor
+	added := make(map[string]bool)
+	var addFile func(fd protoreflect.FileDescriptor)
+	addFile = func(fd protoreflect.FileDescriptor) {
+		if added[fd.Path()] || isWellKnownProto(fd) {
+			return
+		}
+		added[fd.Path()] = true
+		fds = append(fds, fd)
+		for i := 0; i < fd.Imports().Len(); i++ {
+			addFile(fd.Imports().Get(i).FileDescriptor)
+		}
+	}
+	for _, f := range files {
+		addFile(f)
+	}
+	return fds, nil
+}
+
+// well known types are resolved by the compiler or included by --include_imports, rules don't own them
+func isWellKnownProto(fd protoreflect.FileDescriptor) bool {
+	return strings.HasPrefix(fd.Path(), "google/protobuf/")
+}
+
+// loads a binary FileDescriptorSet, linking of the set validates the descriptors
+func loadDescriptorSet(fr *filereader.FileReader, location string) ([]protoreflect.FileDescriptor, error) {
+	raw, err := fr.ReadIntoRawBytes(location)
+	if err != nil {
+		return nil, err
+	}
+
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(raw, &set); err != nil {
+		return nil, err
+	}
+
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, err
+	}
+
+	var fds []protoreflect.FileDescriptor
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		if !isWellKnownProto(fd) {
+			fds = append(fds, fd)
+		}
+		return true
+	})
+	return fds, nil
+}
+
+// ValidateGRPC compiles .proto file or loads a FileDescriptorSet
+// returns the normalized services, rpcs and messages that will be given to the rules
+func ValidateGRPC(fr *filereader.FileReader, location string, logger *CliLogger) (map[string]any, error) {
+	var fds []protoreflect.FileDescriptor
+	var err error
+
+	if isDescriptorSet(location) {
+		logger.Info("Detected Protobuf FileDescriptorSet")
+		logger.Info("Validating by Protobuf specs")
+		fds, err = loadDescriptorSet(fr, location)
+	} else {
+		logger.Info("Detected Protobuf source file")
+		logger.Info("Validating by Protobuf specs")
+		fds, err = compileProtoFile(fr, location)
+	}
+	if err != nil {
+		logger.Error("Failed to meet Protobuf spec")
+		return nil, err
+	}
+
+	schema := &grpcSchema{
+		Files:    []grpcFile{},
+		Services: []grpcService{},
+		Messages: []grpcMessage{},
+		Enums:    []grpcEnum{},
+	}
+	for _, fd := range fds {
+		schema.addFile(fd)
+	}
+	// descriptor set iteration order is not defined, keep the output stable for reports
+	sort.Slice(schema.Files, func(i, j int) bool { return schema.Files[i].Name < schema.Files[j].Name })
+	sort.Slice(schema.Services, func(i, j int) bool { return schema.Services[i].FullName < schema.Services[j].FullName })
+	sort.Slice(schema.Messages, func(i, j int) bool { return schema.Messages[i].FullName < schema.Messages[j].FullName })
+	sort.Slice(schema.Enums, func(i, j int) bool { return schema.Enums[i].FullName < schema.Enums[j].FullName })
+
+	if len(schema.Services) == 0 {
+		logger.Warn("No gRPC services found in the schema")
+	}
+
+	// rules get a plain js object like openapi schema
+	normalized, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiSchemaFile map[string]any
+	if err := json.Unmarshal(normalized, &apiSchemaFile); err != nil {
+		return nil, err
+	}
+
+	return apiSchemaFile, nil
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/1-platform/api-catalog/internal/cli/filereader"
+)
+
+func TestCompileProtoFileImports(t *testing.T) {
+	dir := t.TempDir()
+	writeSpecFiles(t, dir, map[string]string{
+		"petstore.proto": `syntax = "proto3";
+package petstore;
+
+import "google/protobuf/empty.proto";
+import "types/pet.proto";
+
+service PetStore {
+  rpc ListPets(google.protobuf.Empty) returns (types.Pet);
+}
+`,
+		"types/pet.proto": `syntax = "proto3";
+package types;
+
+import "types/common.proto";
+
+message Pet {
+  string name = 1;
+  Status status = 2;
+}
+`,
+		"types/common.proto": `syntax = "proto3";
+package types;
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_AVAILABLE = 1;
+}
+`,
+	})
+
+	fr, err := filereader.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fds, err := compileProtoFile(fr, filepath.Join(dir, "petstore.proto"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var files []string
+	for _, fd := range fds {
+		files = append(files, fd.Path())
+	}
+	// transitive local imports are included, well known types are not
+	want := []string{"petstore.proto", "types/pet.proto", "types/common.proto"}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("files = %v, want %v", files, want)
+	}
+
+	schema, err := ValidateGRPC(fr, filepath.Join(dir, "petstore.proto"), NewCliLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages, _ := schema["messages"].([]any)
+	enums, _ := schema["enums"].([]any)
+	if len(messages) != 1 || messages[0].(map[string]any)["fullName"] != "types.Pet" {
+		t.Errorf("messages = %v, want types.Pet of the imported file", messages)
+	}
+	if len(enums) != 1 || enums[0].(map[string]any)["fullName"] != "types.Status" {
+		t.Errorf("enums = %v, want types.Status of the transitively imported file", enums)
+	}
+}
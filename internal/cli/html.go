@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"bytes"
+	_ "embed"
+	"html/template"
+	"sort"
+	"strings"
+
+	"github.com/1-platform/api-catalog/internal/cli/reportmanager"
+)
+
+//go:embed templates/report.html
+var htmlReportTemplate string
+
+var htmlReport = template.Must(template.New("report").Funcs(template.FuncMap{
+	"scoreClass": func(score float32) string {
+		switch {
+		case score >= 90:
+			return "good"
+		case score >= 50:
+			return "average"
+		default:
+			return "poor"
+		}
+	},
+}).Parse(htmlReportTemplate))
+
+type htmlFinding struct {
+	Rule     string
+	Severity string
+	Method   string
+	Path     string
+	Message  string
+	File     string
+	Line     int
+	Column   int
+}
+
+type htmlReportData struct {
+	Title      string
+	Schema     string
+	Scores     []reportmanager.Score
+	RuleCounts ruleCounts
+	Rules      []string
+	Methods    []string
+	Severities []string
+	Findings   []htmlFinding
+}
+
+func (e *reportExportData) MarshalHTML() ([]byte, error) {
+	data := htmlReportData{
+		Title:      e.title,
+		Schema:     e.schema,
+		Scores:     e.RuleReport.GetTotalScore(),
+		RuleCounts: newRuleCounts(e.Metrics, e.rules, e.ruleErrors),
+		Rules:      []string{},
+		Methods:    []string{},
+		Severities: []string{
+			reportmanager.SeverityError,
+			reportmanager.SeverityWarning,
+			reportmanager.SeverityInfo,
+			reportmanager.SeverityHint,
+		},
+		Findings: []htmlFinding{},
+	}
+	sort.Slice(data.Scores, func(i, j int) bool { return data.Scores[i].Category < data.Scores[j].Category })
+
+	methods := make(map[string]struct{})
+	for rule := range *e.RuleReport {
+		data.Rules = append(data.Rules, rule)
+	}
+	sort.Strings(data.Rules)
+
+	for _, rule := range data.Rules {
+		for _, report := range (*e.RuleReport)[rule].Reports {
+			// rules are not consistent on method casing
+			finding := htmlFinding{
+				Rule:     rule,
+				Severity: report.Severity,
+				Method:   strings.ToLower(report.Method),
+				Path:     report.Path,
+				Message:  report.Message,
+				File:     report.File,
+				Line:     report.Line,
+				Column:   report.Column,
+			}
+			if finding.Method != "" {
+				methods[finding.Method] = struct{}{}
+			}
+			data.Findings = append(data.Findings, finding)
+		}
+	}
+
+	for method := range methods {
+		data.Methods = append(data.Methods, method)
+	}
+	sort.Strings(data.Methods)
+
+	// most severe ones first, rest stays in rule order
+	sort.SliceStable(data.Findings, func(i, j int) bool {
+		return reportmanager.SeverityRank(data.Findings[i].Severity) > reportmanager.SeverityRank(data.Findings[j].Severity)
+	})
+
+	var buf bytes.Buffer
+	if err := htmlReport.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
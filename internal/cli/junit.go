@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/1-platform/api-catalog/internal/cli/reportmanager"
+)
+
+// JUnit XML structure as understood by jenkins and gitlab
+// REF: https://github.com/testmoapp/junitxml
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string         `xml:"name,attr"`
+	ClassName string         `xml:"classname,attr"`
+	Time      float64        `xml:"time,attr,omitempty"`
+	Failures  []junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage  `xml:"error,omitempty"`
+	Skipped   *junitMessage  `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",cdata"`
+}
+
+// method, path and source location of a report for failure body
+func junitReportLocation(report reportmanager.ReportDef) string {
+	var sb strings.Builder
+	if report.Method != "" || report.Path != "" {
+		sb.WriteString(strings.TrimSpace(fmt.Sprintf("%s %s", strings.ToUpper(report.Method), report.Path)))
+		sb.WriteString("\n")
+	}
+	if report.File != "" {
+		sb.WriteString(fmt.Sprintf("%s:%d:%d\n", report.File, report.Line, report.Column))
+	} else if report.Pointer != "" {
+		sb.WriteString(report.Pointer + "\n")
+	}
+	return sb.String()
+}
+
+func (e *reportExportData) MarshalJUnit() ([]byte, error) {
+	ruleSet := make(map[string]struct{}, len(e.rules))
+	for rule := range e.rules {
+		ruleSet[rule] = struct{}{}
+	}
+	for rule := range *e.RuleReport {
+		ruleSet[rule] = struct{}{}
+	}
+	ruleNames := make([]string, 0, len(ruleSet))
+	for rule := range ruleSet {
+		ruleNames = append(ruleNames, rule)
+	}
+	sort.Strings(ruleNames)
+
+	suite := junitTestSuite{Name: fmt.Sprintf("apic.%s", e.suite), TestCases: []junitTestCase{}}
+	for _, rule := range ruleNames {
+		tc := junitTestCase{Name: rule, ClassName: suite.Name}
+		if e.Metrics != nil {
+			// junit time is in seconds, rounded to milliseconds
+			tc.Time = math.Round(e.Metrics.RuleTimings[rule]) / 1000
+		}
+
+		if opt, ok := e.rules[rule]; ok && opt.Disable {
+			tc.Skipped = &junitMessage{Message: "rule is disabled"}
+			suite.Skipped++
+		} else if ruleErr, ok := e.ruleErrors[rule]; ok {
+			tc.Error = &junitMessage{Message: "rule failed", Type: "exception", Body: ruleErr}
+			suite.Errors++
+		} else {
+			// every report is a failure with its severity as type
+			for _, report := range (*e.RuleReport)[rule].Reports {
+				tc.Failures = append(tc.Failures, junitMessage{Message: report.Message, Type: report.Severity, Body: junitReportLocation(report)})
+			}
+			if len(tc.Failures) > 0 {
+				suite.Failures++
+			}
+		}
+
+		suite.TestCases = append(suite.TestCases, tc)
+		suite.Tests++
+	}
+
+	out, err := xml.MarshalIndent(junitTestSuites{
+		Name:     "apic",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Skipped:  suite.Skipped,
+		Suites:   []junitTestSuite{suite},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), out...), nil
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"encoding/xml"
+	"reflect"
+	"testing"
+
+	"github.com/1-platform/api-catalog/internal/cli/pluginmanager"
+	"github.com/1-platform/api-catalog/internal/cli/reportmanager"
+)
+
+// export of a run with a failing, a passing, a disabled and an errored rule
+func testExportData() *reportExportData {
+	rm := reportmanager.New()
+	rm.PushReport("url_case_checker", reportmanager.ReportDef{
+		Method: "get", Path: "/Pets", Message: "url is not kebabcase", Severity: reportmanager.SeverityWarning,
+		Pointer: "/paths/~1Pets/get", File: "openapi.yaml", Line: 7, Column: 5,
+	})
+	rm.PushReport("url_case_checker", reportmanager.ReportDef{
+		Message: "url is too long", Severity: reportmanager.SeverityHint, Pointer: "/paths/~1Pets",
+	})
+	rm.PushReport("openapi_spec_validation", reportmanager.ReportDef{
+		Message: "invalid schema", Severity: reportmanager.SeverityError,
+	})
+
+	return &reportExportData{
+		Metrics:    &reportRuleMetrics{TotalRules: 4, PassedRules: 2, RuleTimings: map[string]float64{"url_case_checker": 1500}},
+		RuleReport: &rm,
+		rules: map[string]*pluginmanager.PluginRule{
+			"url_case_checker":    {Severity: reportmanager.SeverityWarning},
+			"status_code_check":   {Severity: reportmanager.SeverityInfo},
+			"schema_case_checker": {Disable: true},
+			"broken_rule":         {},
+		},
+		ruleErrors: map[string]string{"broken_rule": "TypeError: x is undefined"},
+		suite:      "openapi",
+		schema:     "openapi.yaml",
+	}
+}
+
+func TestMarshalJUnit(t *testing.T) {
+	out, err := testExportData().MarshalJUnit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got junitTestSuites
+	if err := xml.Unmarshal(out, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Tests != 5 || got.Failures != 2 || got.Errors != 1 || got.Skipped != 1 {
+		t.Errorf("tests, failures, errors, skipped = %d, %d, %d, %d, want 5, 2, 1, 1", got.Tests, got.Failures, got.Errors, got.Skipped)
+	}
+	testCases := make(map[string]junitTestCase)
+	for _, tc := range got.Suites[0].TestCases {
+		if tc.ClassName != "apic.openapi" {
+			t.Errorf("%s classname = %s, want apic.openapi", tc.Name, tc.ClassName)
+		}
+		testCases[tc.Name] = tc
+	}
+
+	// every report is a failure, including the hints
+	wantFailures := []junitMessage{
+		{Message: "url is not kebabcase", Type: reportmanager.SeverityWarning, Body: "GET /Pets\nopenapi.yaml:7:5\n"},
+		{Message: "url is too long", Type: reportmanager.SeverityHint, Body: "/paths/~1Pets\n"},
+	}
+	if tc := testCases["url_case_checker"]; !reflect.DeepEqual(tc.Failures, wantFailures) {
+		t.Errorf("url_case_checker failures = %v, want %v", tc.Failures, wantFailures)
+	}
+	if tc := testCases["url_case_checker"]; tc.Time != 1.5 {
+		t.Errorf("url_case_checker time = %v, want 1.5", tc.Time)
+	}
+	if tc := testCases["openapi_spec_validation"]; len(tc.Failures) != 1 || tc.Failures[0].Type != reportmanager.SeverityError {
+		t.Errorf("openapi_spec_validation failures = %v, want the error report", tc.Failures)
+	}
+	if tc := testCases["status_code_check"]; len(tc.Failures) != 0 || tc.Error != nil || tc.Skipped != nil {
+		t.Errorf("status_code_check = %+v, want a passed testcase", tc)
+	}
+	if tc := testCases["schema_case_checker"]; tc.Skipped == nil {
+		t.Errorf("schema_case_checker = %+v, want skipped", tc)
+	}
+	if tc := testCases["broken_rule"]; tc.Error == nil || tc.Error.Body != "TypeError: x is undefined" {
+		t.Errorf("broken_rule error = %v, want the exception", tc.Error)
+	}
+}
@@ -64,10 +64,24 @@ func (l *CliLogger) Divider() {
 	fmt.Println(divider)
 }
 
-func (l *CliLogger) Report(rule, method, path, message string) {
+func severityStyle(severity string) lipgloss.Style {
+	switch severity {
+	case reportmanager.SeverityError:
+		return errorTopic
+	case reportmanager.SeverityWarning:
+		return warnTopic
+	case reportmanager.SeverityInfo:
+		return infoTopic
+	default:
+		return logTopic
+	}
+}
+
+func (l *CliLogger) Report(rule, severity, method, path, message string) {
 	var sb strings.Builder
 
 	sb.WriteString(fmt.Sprintf("%s%s\n", reportTemplateTitle.Render("Rule:"), reportTemplateValue.Render(rule)))
+	sb.WriteString(fmt.Sprintf("%s%s\n", reportTemplateTitle.Render("Severity:"), reportTemplateValue.Render(severityStyle(severity).Render(severity))))
 	sb.WriteString(fmt.Sprintf("%s%s\n", reportTemplateTitle.Render("Method:"), reportTemplateValue.Render(method)))
 	sb.WriteString(fmt.Sprintf("%s%s\n", reportTemplateTitle.Render("Path:"), reportTemplateValue.Render(path)))
 	sb.WriteString(fmt.Sprintf("%s%s", reportTemplateTitle.Render("Message:"), reportTemplateValue.Render(message)))
@@ -81,11 +95,14 @@ func (l *CliLogger) Title(info string) {
 	l.Divider()
 }
 
-func (l *CliLogger) RuleMetrics(passed int, total int) {
+func (l *CliLogger) RuleMetrics(counts ruleCounts) {
 	l.Divider()
-	fmt.Println(infoTopic.Render(fmt.Sprintf("  Total Rules: %d", total)))
-	fmt.Println(successTopic.Render(fmt.Sprintf("  Passed Rules: %d", passed)))
-	fmt.Println(errorTopic.Render(fmt.Sprintf("  Failed Rules: %d", total-passed)))
+	fmt.Println(infoTopic.Render(fmt.Sprintf("  Total Rules: %d", counts.Total)))
+	fmt.Println(successTopic.Render(fmt.Sprintf("  Passed Rules: %d", counts.Passed)))
+	fmt.Println(errorTopic.Render(fmt.Sprintf("  Failed Rules: %d", counts.Failed)))
+	if counts.Disabled > 0 {
+		fmt.Println(infoTopic.Render(fmt.Sprintf("  Disabled Rules: %d", counts.Disabled)))
+	}
 	l.Divider()
 }
 
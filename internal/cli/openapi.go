@@ -1,62 +1,396 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/url"
+	"sort"
 	"strings"
 
+	"github.com/1-platform/api-catalog/internal/cli/compiler"
+	"github.com/1-platform/api-catalog/internal/cli/filereader"
+	"github.com/1-platform/api-catalog/internal/cli/reportmanager"
 	"github.com/getkin/kin-openapi/openapi2"
 	"github.com/getkin/kin-openapi/openapi2conv"
 	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/goccy/go-json"
 	"github.com/invopop/yaml"
 )
 
-func ValidateOpenAPI(raw []byte, apiSchemaFile map[string]any, logger *CliLogger) error {
-	if val, ok := apiSchemaFile["swagger"]; ok && (strings.HasPrefix(val.(string), "2.") || val.(string) == "2") {
+// loader that resolves external refs relative to the schema location
+// files are read by filereader thus both local and remote refs are supported
+func newOpenAPILoader(fr *filereader.FileReader) *openapi3.Loader {
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = func(_ *openapi3.Loader, location *url.URL) ([]byte, error) {
+		return fr.ReadIntoRawBytes(location.String())
+	}
+	return loader
+}
+
+// ValidateOpenAPI validates the schema and returns the typed v3 document
+// along with the v3 schema map, v2 schemas are converted to v3 for both
+func ValidateOpenAPI(raw []byte, apiSchemaFile map[string]any, location string, fr *filereader.FileReader, logger *CliLogger) (*openapi3.T, map[string]any, error) {
+	locationURL, err := filereader.LocationURL(location)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// yaml parses unquoted swagger: 2.0 as number
+	if val, ok := apiSchemaFile["swagger"]; ok && (strings.HasPrefix(fmt.Sprint(val), "2.") || fmt.Sprint(val) == "2") {
 		logger.Info("Detected OpenAPI v2 schema")
 		logger.Info("Converting the schema to v3")
+		// converter allows only local refs, v3 pointers like #/components/schemas are not in v2 files
+		bundled, err := fr.ResolveExternalRefs(location, apiSchemaFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		// kin openapi expects swagger version to be string
+		v2SchemaFile := make(map[string]any, len(bundled))
+		for k, v := range bundled {
+			v2SchemaFile[k] = v
+		}
+		v2SchemaFile["swagger"] = "2.0"
+		if raw, err = json.Marshal(v2SchemaFile); err != nil {
+			return nil, nil, err
+		}
+
 		// convert to OpenAPIv3
 		var docv2 openapi2.T
-		if err := yaml.Unmarshal(raw, &docv2); err != nil {
-			return err
+		if err := json.Unmarshal(raw, &docv2); err != nil {
+			return nil, nil, err
 		}
 		docv3, err := openapi2conv.ToV3(&docv2)
 		if err != nil {
-			return nil
+			return nil, nil, fmt.Errorf("failed to convert v2 schema to v3: %w", err)
 		}
 
 		logger.Success("Converted v2 to v3 schema")
-		loader := openapi3.NewLoader()
-
-		logger.Info("Validating by OpenAPI schema specs")
-		if err = docv3.Validate(loader.Context); err != nil {
-			logger.Error("Failed to meet OpenAPI spec")
-			fmt.Println(err)
+		loader := newOpenAPILoader(fr)
+		if err := loader.ResolveRefsIn(docv3, locationURL); err != nil {
+			return nil, nil, err
 		}
 
 		// now we need the openapi v3 version of map[strings]
 		if raw, err = docv3.MarshalJSON(); err != nil {
-			return err
+			return nil, nil, err
 		}
 
-		if err = yaml.Unmarshal(raw, &apiSchemaFile); err != nil {
-			return err
+		var v3SchemaFile map[string]any
+		if err = yaml.Unmarshal(raw, &v3SchemaFile); err != nil {
+			return nil, nil, err
 		}
 
-		return nil
+		return docv3, v3SchemaFile, nil
 	}
 	logger.Info("Detected OpenAPI v3 schema")
-	loader := openapi3.NewLoader()
-	docv3, err := loader.LoadFromData(raw)
+	loader := newOpenAPILoader(fr)
+	docv3, err := loader.LoadFromDataWithPath(raw, locationURL)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+
+	return docv3, apiSchemaFile, nil
+}
+
+// json pointer of an operation in schema, method is optional
+// rules reporting all methods of a path like "GET, POST" points to the path
+func openAPIPointer(path string, method string) string {
+	pointer := "/paths/" + filereader.EscapePointerToken(path)
+	if method != "" && !strings.Contains(method, ",") {
+		pointer += "/" + strings.ToLower(method)
+	}
+	return pointer
+}
+
+// json pointer of a report that gives path and method instead of a pointer
+// empty when the path is not in the schema, like the placeholders some rules report
+func openAPIReportPointer(schema map[string]any, path string, method string) string {
+	paths, _ := schema["paths"].(map[string]any)
+	pathItem, ok := paths[path].(map[string]any)
+	if !ok {
+		return ""
+	}
+	if _, ok := pathItem[strings.ToLower(method)].(map[string]any); !ok {
+		method = ""
 	}
+	return openAPIPointer(path, method)
+}
 
-	logger.Info("Validating by OpenAPI schema specs")
-	if err := docv3.Validate(loader.Context); err != nil {
-		logger.Error("Failed to meet OpenAPI spec")
-		fmt.Println(err)
+// builtin rule under which spec violations are reported
+const openAPISpecValidationRule = "openapi_spec_validation"
 
+// ValidateOpenAPISpec validates each element of the document separately
+// thus every violation is reported with its json pointer instead of only the first one
+// returns the violations and number of elements validated
+func ValidateOpenAPISpec(doc *openapi3.T) ([]reportmanager.ReportDef, int) {
+	ctx := context.Background()
+	var findings []reportmanager.ReportDef
+	checked := 0
+
+	check := func(pointer string, err error) bool {
+		checked++
+		if err == nil {
+			return true
+		}
+		finding := reportmanager.ReportDef{Message: err.Error(), Pointer: pointer}
+		// operation level violations gets path and method
+		if tokens := strings.Split(pointer, "/"); len(tokens) > 2 && tokens[1] == "paths" {
+			finding.Path = strings.ReplaceAll(strings.ReplaceAll(tokens[2], "~1", "/"), "~0", "~")
+			if len(tokens) > 3 {
+				finding.Method = tokens[3]
+			}
+		}
+		findings = append(findings, finding)
+		return false
+	}
+
+	if doc.OpenAPI == "" {
+		check("/openapi", errors.New("value of openapi must be a non-empty string"))
+	}
+
+	if doc.Info == nil {
+		check("/info", errors.New("must be an object"))
+	} else {
+		check("/info", doc.Info.Validate(ctx))
+	}
+
+	for i, server := range doc.Servers {
+		check(fmt.Sprintf("/servers/%d", i), server.Validate(ctx))
+	}
+
+	for i, tag := range doc.Tags {
+		check(fmt.Sprintf("/tags/%d", i), tag.Validate(ctx))
+	}
+
+	if doc.Security != nil {
+		check("/security", doc.Security.Validate(ctx))
+	}
+
+	if doc.ExternalDocs != nil {
+		check("/externalDocs", doc.ExternalDocs.Validate(ctx))
+	}
+
+	if doc.Paths == nil {
+		check("/paths", errors.New("value of paths must be an object"))
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	pathsValid := true
+	for _, path := range paths {
+		pathItem := doc.Paths[path]
+		pointer := "/paths/" + filereader.EscapePointerToken(path)
+
+		// operations are validated first to point to the exact operation
+		valid := true
+		if pathItem != nil {
+			for _, method := range []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"} {
+				if op := pathItem.GetOperation(strings.ToUpper(method)); op != nil {
+					valid = check(pointer+"/"+method, op.Validate(ctx)) && valid
+				}
+			}
+		}
+		// then path level checks like path params definition
+		if valid {
+			valid = check(pointer, openapi3.Paths{path: pathItem}.Validate(ctx))
+		}
+		pathsValid = pathsValid && valid
+	}
+	// checks across the paths like conflicting paths
+	if doc.Paths != nil && pathsValid {
+		check("/paths", doc.Paths.Validate(ctx))
+	}
+
+	if c := doc.Components; c != nil {
+		for name, v := range c.Schemas {
+			check("/components/schemas/"+filereader.EscapePointerToken(name), (&openapi3.Components{Schemas: openapi3.Schemas{name: v}}).Validate(ctx))
+		}
+		for name, v := range c.Parameters {
+			check("/components/parameters/"+filereader.EscapePointerToken(name), (&openapi3.Components{Parameters: openapi3.ParametersMap{name: v}}).Validate(ctx))
+		}
+		for name, v := range c.RequestBodies {
+			check("/components/requestBodies/"+filereader.EscapePointerToken(name), (&openapi3.Components{RequestBodies: openapi3.RequestBodies{name: v}}).Validate(ctx))
+		}
+		for name, v := range c.Responses {
+			check("/components/responses/"+filereader.EscapePointerToken(name), (&openapi3.Components{Responses: openapi3.Responses{name: v}}).Validate(ctx))
+		}
+		for name, v := range c.Headers {
+			check("/components/headers/"+filereader.EscapePointerToken(name), (&openapi3.Components{Headers: openapi3.Headers{name: v}}).Validate(ctx))
+		}
+		for name, v := range c.SecuritySchemes {
+			check("/components/securitySchemes/"+filereader.EscapePointerToken(name), (&openapi3.Components{SecuritySchemes: openapi3.SecuritySchemes{name: v}}).Validate(ctx))
+		}
+		for name, v := range c.Examples {
+			check("/components/examples/"+filereader.EscapePointerToken(name), (&openapi3.Components{Examples: openapi3.Examples{name: v}}).Validate(ctx))
+		}
+		for name, v := range c.Links {
+			check("/components/links/"+filereader.EscapePointerToken(name), (&openapi3.Components{Links: openapi3.Links{name: v}}).Validate(ctx))
+		}
+		for name, v := range c.Callbacks {
+			check("/components/callbacks/"+filereader.EscapePointerToken(name), (&openapi3.Components{Callbacks: openapi3.Callbacks{name: v}}).Validate(ctx))
+		}
+	}
+
+	// the whole document is validated as well thus nothing missed by the element checks passes
+	if len(findings) == 0 {
+		if err := doc.Validate(ctx); err != nil {
+			check("", err)
+		}
+	}
+
+	// map iteration is random, keep the findings stable
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Pointer < findings[j].Pointer })
+
+	return findings, checked
+}
+
+// kin openapi schema to plain map as rules get in raw schema
+func schemaToMap(ref *openapi3.SchemaRef) map[string]any {
+	if ref == nil || ref.Value == nil {
+		return nil
+	}
+
+	raw, err := json.Marshal(ref.Value)
+	if err != nil {
+		return nil
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil
+	}
+	return schema
+}
+
+func contentToMap(content openapi3.Content) map[string]map[string]any {
+	out := make(map[string]map[string]any, len(content))
+	for mediaType, val := range content {
+		out[mediaType] = schemaToMap(val.Schema)
+	}
+	return out
+}
+
+// OpenAPIOperations flattens the paths of v3 document into a list of operations
+// sorted by path and then by method for stable reports
+func OpenAPIOperations(doc *openapi3.T) []compiler.Operation {
+	operations := []compiler.Operation{}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		pathItem := doc.Paths[path]
+		// like a path without a value in yaml
+		if pathItem == nil {
+			continue
+		}
+		for _, method := range []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"} {
+			op := pathItem.GetOperation(strings.ToUpper(method))
+			if op == nil {
+				continue
+			}
+
+			operation := compiler.Operation{
+				Path:        path,
+				Method:      method,
+				Pointer:     openAPIPointer(path, method),
+				OperationID: op.OperationID,
+				Summary:     op.Summary,
+				Tags:        op.Tags,
+				Deprecated:  op.Deprecated,
+				Parameters:  []compiler.OperationParameter{},
+				Responses:   []compiler.OperationResponse{},
+				Security:    []map[string][]string{},
+			}
+			if operation.Tags == nil {
+				operation.Tags = []string{}
+			}
+
+			// operation parameters overrides path level parameter with same name and location
+			params := make(map[string]int)
+			for i, p := range append(append(openapi3.Parameters{}, pathItem.Parameters...), op.Parameters...) {
+				if p == nil || p.Value == nil {
+					continue
+				}
+				param := compiler.OperationParameter{
+					Name:       p.Value.Name,
+					In:         p.Value.In,
+					Required:   p.Value.Required,
+					Deprecated: p.Value.Deprecated,
+					Schema:     schemaToMap(p.Value.Schema),
+					Pointer:    fmt.Sprintf("%s/parameters/%d", operation.Pointer, i-len(pathItem.Parameters)),
+				}
+				if i < len(pathItem.Parameters) {
+					param.Pointer = fmt.Sprintf("%s/parameters/%d", openAPIPointer(path, ""), i)
+				}
+				// parameters can have schema inside content instead
+				if param.Schema == nil {
+					for _, mt := range p.Value.Content {
+						param.Schema = schemaToMap(mt.Schema)
+						break
+					}
+				}
+
+				key := p.Value.In + ":" + p.Value.Name
+				if i, ok := params[key]; ok {
+					operation.Parameters[i] = param
+				} else {
+					params[key] = len(operation.Parameters)
+					operation.Parameters = append(operation.Parameters, param)
+				}
+			}
+
+			if op.RequestBody != nil && op.RequestBody.Value != nil {
+				operation.RequestBody = &compiler.OperationRequestBody{
+					Required: op.RequestBody.Value.Required,
+					Content:  contentToMap(op.RequestBody.Value.Content),
+				}
+			}
+
+			statusCodes := make([]string, 0, len(op.Responses))
+			for code := range op.Responses {
+				statusCodes = append(statusCodes, code)
+			}
+			sort.Strings(statusCodes)
+			for _, code := range statusCodes {
+				res := op.Responses[code]
+				if res == nil || res.Value == nil {
+					continue
+				}
+				response := compiler.OperationResponse{
+					StatusCode: code,
+					Content:    contentToMap(res.Value.Content),
+					Headers:    []string{},
+				}
+				if res.Value.Description != nil {
+					response.Description = *res.Value.Description
+				}
+				for header := range res.Value.Headers {
+					response.Headers = append(response.Headers, header)
+				}
+				sort.Strings(response.Headers)
+				operation.Responses = append(operation.Responses, response)
+			}
+
+			security := doc.Security
+			if op.Security != nil {
+				security = *op.Security
+			}
+			for _, requirement := range security {
+				operation.Security = append(operation.Security, requirement)
+			}
+
+			operations = append(operations, operation)
+		}
 	}
 
-	return nil
+	return operations
 }
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestOpenAPIOperations(t *testing.T) {
+	doc := loadDiffSpec(t, `
+  /stores:
+    get:
+      responses:
+        "200": {description: ok}
+  /pets/{id}:
+    parameters:
+      - {name: id, in: path, required: true, schema: {type: string}}
+      - {name: fields, in: query, schema: {type: string}}
+    get:
+      parameters:
+        - {name: fields, in: query, required: true, schema: {type: string}}
+      responses:
+        "200": {description: ok}
+    delete:
+      responses:
+        "204": {description: deleted}
+`)
+	// a path without a value is skipped instead of panicking
+	doc.Paths["/owners"] = nil
+
+	type param struct{ name, pointer string }
+	var got []string
+	params := make(map[string][]param)
+	for _, op := range OpenAPIOperations(doc) {
+		got = append(got, op.Method+" "+op.Path+" "+op.Pointer)
+		for _, p := range op.Parameters {
+			params[op.Method+" "+op.Path] = append(params[op.Method+" "+op.Path], param{p.Name, p.Pointer})
+		}
+	}
+
+	want := []string{
+		"get /pets/{id} /paths/~1pets~1{id}/get",
+		"delete /pets/{id} /paths/~1pets~1{id}/delete",
+		"get /stores /paths/~1stores/get",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("operations = %v, want %v", got, want)
+	}
+
+	// the operation parameter overrides the path level one at its own pointer
+	wantParams := map[string][]param{
+		"get /pets/{id}": {
+			{"id", "/paths/~1pets~1{id}/parameters/0"},
+			{"fields", "/paths/~1pets~1{id}/get/parameters/0"},
+		},
+		"delete /pets/{id}": {
+			{"id", "/paths/~1pets~1{id}/parameters/0"},
+			{"fields", "/paths/~1pets~1{id}/parameters/1"},
+		},
+	}
+	if !reflect.DeepEqual(params, wantParams) {
+		t.Errorf("parameters = %v, want %v", params, wantParams)
+	}
+
+	if ops := OpenAPIOperations(&openapi3.T{Paths: openapi3.Paths{"/pets": nil}}); len(ops) != 0 {
+		t.Errorf("operations of nil path item = %v, want none", ops)
+	}
+}
@@ -8,6 +8,10 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
+
+	"github.com/1-platform/api-catalog/internal/cli/compiler/modules"
+	"github.com/1-platform/api-catalog/internal/cli/reportmanager"
 )
 
 var snakeCaseRegex = regexp.MustCompile("^[a-z0-9]+(?:_[a-z0-9]+)*$")
@@ -32,11 +36,73 @@ type PluginRule struct {
 	File    string
 	Disable bool
 	Options map[string]any
+	// default severity of the reports by the rule
+	Severity string
+	// capabilities like exec, env-read the rule needs from builtin modules
+	Capabilities []string
+	// capabilities granted in apic.toml, only set by the overrides thus a plugin can't grant itself
+	Grant []string `json:"-" yaml:"-" toml:"-" mapstructure:"-"`
+}
+
+func (r *PluginRule) isGranted(capability string) bool {
+	for _, g := range r.Grant {
+		if g == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// GrantedCapabilities are the capabilities the rule declared and the user granted
+func (r *PluginRule) GrantedCapabilities() []string {
+	var granted []string
+	for _, c := range r.Capabilities {
+		if r.isGranted(c) {
+			granted = append(granted, c)
+		}
+	}
+	return granted
+}
+
+// MissingCapabilities are the capabilities the rule declared but the user didn't grant
+func (r *PluginRule) MissingCapabilities() []string {
+	var missing []string
+	for _, c := range r.Capabilities {
+		if !r.isGranted(c) {
+			missing = append(missing, c)
+		}
+	}
+	return missing
 }
 
 type PluginUserOverride struct {
-	Disable *bool          `json:"omitempty" yaml:"omitempty" toml:"omitempty"`
-	Options map[string]any `json:"omitempty" yaml:"omitempty" toml:"omitempty"`
+	Disable  *bool          `json:",omitempty" yaml:",omitempty" toml:",omitempty"`
+	Options  map[string]any `json:",omitempty" yaml:",omitempty" toml:",omitempty"`
+	Severity string         `json:",omitempty" yaml:",omitempty" toml:",omitempty"`
+	// capabilities granted to the rule
+	Grant []string `json:",omitempty" yaml:",omitempty" toml:",omitempty"`
+}
+
+// rules without severity reports as warning
+const defaultSeverity = reportmanager.SeverityWarning
+
+func ruleSeverity(rule string, severity string) (string, error) {
+	if severity == "" {
+		return defaultSeverity, nil
+	}
+	if reportmanager.SeverityRank(severity) < 0 {
+		return "", fmt.Errorf("invalid severity %s for rule %s. Allowed values: error, warning, info, hint", severity, rule)
+	}
+	return severity, nil
+}
+
+func validateCapabilities(rule string, capabilities []string) error {
+	for _, c := range capabilities {
+		if !modules.IsCapability(c) {
+			return fmt.Errorf("invalid capability %s for rule %s. Allowed values: %s", c, rule, strings.Join(modules.Capabilities, ", "))
+		}
+	}
+	return nil
 }
 
 type PluginConfFile struct {
@@ -93,7 +159,14 @@ func (p *PluginManager) LoadBuiltinPlugin() error {
 	// load up the rules
 	for rule, conf := range pluginCfg.Rules {
 		jsRuleFile := filepath.Join(path, fmt.Sprintf("/%s", conf.File))
-		p.Rules[rule] = &PluginRule{Disable: conf.Disable, File: jsRuleFile, Options: conf.Options}
+		severity, err := ruleSeverity(rule, conf.Severity)
+		if err != nil {
+			return err
+		}
+		if err := validateCapabilities(rule, conf.Capabilities); err != nil {
+			return err
+		}
+		p.Rules[rule] = &PluginRule{Disable: conf.Disable, File: jsRuleFile, Options: conf.Options, Severity: severity, Capabilities: conf.Capabilities}
 	}
 
 	return nil
@@ -112,7 +185,14 @@ func (p *PluginManager) LoadUserPlugins(userPlugins PluginConfFile) error {
 			fmt.Printf("Warning: %s is already defined. Overriding it.\n", rule)
 		}
 
-		p.Rules[rule] = &PluginRule{Disable: conf.Disable, File: conf.File, Options: conf.Options}
+		severity, err := ruleSeverity(rule, conf.Severity)
+		if err != nil {
+			return err
+		}
+		if err := validateCapabilities(rule, conf.Capabilities); err != nil {
+			return err
+		}
+		p.Rules[rule] = &PluginRule{Disable: conf.Disable, File: conf.File, Options: conf.Options, Severity: severity, Capabilities: conf.Capabilities}
 	}
 
 	return nil
@@ -124,6 +204,19 @@ func (p *PluginManager) OverrideRules(userOverrides map[string]PluginUserOverrid
 			if conf.Disable != nil {
 				val.Disable = *conf.Disable
 			}
+			if conf.Severity != "" {
+				severity, err := ruleSeverity(rule, conf.Severity)
+				if err != nil {
+					return err
+				}
+				val.Severity = severity
+			}
+			if conf.Grant != nil {
+				if err := validateCapabilities(rule, conf.Grant); err != nil {
+					return err
+				}
+				val.Grant = conf.Grant
+			}
 			if conf.Options != nil {
 				for i, r := range conf.Options {
 					if val.Options == nil {
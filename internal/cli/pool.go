@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/1-platform/api-catalog/internal/cli/compiler"
+	"github.com/1-platform/api-catalog/internal/cli/pluginmanager"
+	"github.com/1-platform/api-catalog/internal/cli/reportmanager"
+	"github.com/dop251/goja"
+	"github.com/goccy/go-json"
+)
+
+// returned for rules that didn't finish within the rule or run timeout
+var errRuleTimeout = errors.New("rule timed out")
+
+type ruleRunOutput struct {
+	err      error
+	duration time.Duration
+}
+
+func sortedRuleNames(rules map[string]*pluginmanager.PluginRule) []string {
+	names := make([]string, 0, len(rules))
+	for rule := range rules {
+		names = append(names, rule)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedReportRules(rm reportmanager.ReportManager) []string {
+	names := make([]string, 0, len(rm))
+	for rule := range rm {
+		names = append(names, rule)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// rules can modify the schema given to them, each rule run gets its own copy
+// thus a rule never sees the changes made by other rules, even the ones run earlier on the same runtime
+func copySchema(node any) any {
+	return copySchemaNode(node, make(map[uintptr]any))
+}
+
+// nodes shared by refs to the same schema are copied once and stay shared in the copy
+// otherwise the copy grows exponentially with nested refs
+func copySchemaNode(node any, copied map[uintptr]any) any {
+	switch val := node.(type) {
+	case map[string]any:
+		key := reflect.ValueOf(val).Pointer()
+		if out, ok := copied[key]; ok {
+			return out
+		}
+		out := make(map[string]any, len(val))
+		copied[key] = out
+		for k, v := range val {
+			out[k] = copySchemaNode(v, copied)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, v := range val {
+			out[i] = copySchemaNode(v, copied)
+		}
+		return out
+	default:
+		return node
+	}
+}
+
+func copyOperations(operations []compiler.Operation) []compiler.Operation {
+	if operations == nil {
+		return nil
+	}
+	raw, err := json.Marshal(operations)
+	if err != nil {
+		return operations
+	}
+	var out []compiler.Operation
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return operations
+	}
+	return out
+}
+
+// runs the rules concurrently over a pool of runtimes
+// returns the outcome of each rule, order of the output doesn't depend on the pool
+func runRulePool(
+	cmp *compiler.Compiler,
+	ruleNames []string,
+	programs map[string]*goja.Program,
+	rules map[string]*pluginmanager.PluginRule,
+	schema map[string]any,
+	operations []compiler.Operation,
+	newRunConfig func(rule string, schema map[string]any, ops []compiler.Operation) *compiler.RunConfig,
+) map[string]*ruleRunOutput {
+	workers := concurrency
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(ruleNames) {
+		workers = len(ruleNames)
+	}
+
+	// every rule has its own slot thus workers never write to the map
+	results := make(map[string]*ruleRunOutput, len(ruleNames))
+	for _, rule := range ruleNames {
+		results[rule] = &ruleRunOutput{}
+	}
+
+	// rules not started before the run timeout are failed without running
+	ctx := context.Background()
+	if runTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, runTimeout)
+		defer cancel()
+	}
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runner := cmp.NewRunner()
+
+			for rule := range jobs {
+				if ctx.Err() != nil {
+					results[rule].err = fmt.Errorf("%w: run timeout of %s exceeded before the rule started", errRuleTimeout, runTimeout)
+					continue
+				}
+
+				ruleSchema, _ := copySchema(schema).(map[string]any)
+				cfg := newRunConfig(rule, ruleSchema, copyOperations(operations))
+
+				ruleCtx, cancel := ctx, context.CancelFunc(func() {})
+				if ruleTimeout > 0 {
+					ruleCtx, cancel = context.WithTimeout(ctx, ruleTimeout)
+				}
+
+				start := time.Now()
+				err := runner.Run(ruleCtx, programs[rule], cfg, rules[rule].Options)
+				cancel()
+				if errors.Is(err, compiler.ErrRuleInterrupted) {
+					if ctx.Err() != nil {
+						err = fmt.Errorf("%w: run timeout of %s exceeded", errRuleTimeout, runTimeout)
+					} else {
+						err = fmt.Errorf("%w: rule didn't finish within %s", errRuleTimeout, ruleTimeout)
+					}
+				}
+				results[rule].err = err
+				results[rule].duration = time.Since(start)
+			}
+		}()
+	}
+
+	for _, rule := range ruleNames {
+		jobs <- rule
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/1-platform/api-catalog/internal/cli/compiler"
+	"github.com/1-platform/api-catalog/internal/cli/compiler/modules"
+	"github.com/1-platform/api-catalog/internal/cli/pluginmanager"
+	"github.com/1-platform/api-catalog/internal/cli/reportmanager"
+	"github.com/dop251/goja"
+)
+
+type testLogger struct{}
+
+func (testLogger) Info(string)  {}
+func (testLogger) Warn(string)  {}
+func (testLogger) Error(string) {}
+func (testLogger) Log(string)   {}
+
+// programs are compiled like Compiler.Transform does without babel
+func compileRule(t *testing.T, code string) *goja.Program {
+	t.Helper()
+	pgm, err := goja.Compile("test", fmt.Sprintf("(function(exports, require, module){\n%s\n})", code), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pgm
+}
+
+// every rule reports once per operation and sets the score from its index
+const reportingRule = `
+exports.default = function (config, options) {
+  config.operations.forEach(function (op) {
+    config.report({ path: op.path, method: op.method, message: "found " + options.n });
+  });
+  config.setScore("quality", options.n);
+};
+`
+
+// rules see the schema given to the pool, not the changes of other rules
+const mutatingRule = `
+exports.default = function (config) {
+  config.report({ message: "title " + config.schema.info.title });
+  config.schema.info.title = "mutated";
+  config.operations.length = 0;
+};
+`
+
+func TestRunRulePool(t *testing.T) {
+	prevConcurrency, prevRuleTimeout, prevRunTimeout := concurrency, ruleTimeout, runTimeout
+	t.Cleanup(func() {
+		concurrency, ruleTimeout, runTimeout = prevConcurrency, prevRuleTimeout, prevRunTimeout
+	})
+
+	cmp, err := compiler.New(testLogger{}, "", modules.Config{Root: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema := map[string]any{"info": map[string]any{"title": "pets"}}
+	operations := []compiler.Operation{
+		{Path: "/pets", Method: "get"},
+		{Path: "/pets", Method: "post"},
+		{Path: "/pets/{id}", Method: "get"},
+	}
+
+	rules := make(map[string]*pluginmanager.PluginRule)
+	programs := make(map[string]*goja.Program)
+	var ruleNames []string
+	for i := 0; i < 24; i++ {
+		rule := fmt.Sprintf("rule_%02d", i)
+		rules[rule] = &pluginmanager.PluginRule{Options: map[string]any{"n": i}}
+		programs[rule] = compileRule(t, reportingRule)
+		ruleNames = append(ruleNames, rule)
+	}
+	for i := 0; i < 4; i++ {
+		rule := fmt.Sprintf("mutating_%d", i)
+		rules[rule] = &pluginmanager.PluginRule{}
+		programs[rule] = compileRule(t, mutatingRule)
+		ruleNames = append(ruleNames, rule)
+	}
+	rules["throwing"] = &pluginmanager.PluginRule{}
+	programs["throwing"] = compileRule(t, `exports.default = function () { throw new Error("boom"); };`)
+	rules["looping"] = &pluginmanager.PluginRule{}
+	programs["looping"] = compileRule(t, `exports.default = function () { while (true) {} };`)
+	rules["recursing"] = &pluginmanager.PluginRule{}
+	programs["recursing"] = compileRule(t, `function deeper(n) { return deeper(n + 1) + 1; }
+exports.default = function () { deeper(0); };`)
+	ruleNames = append(ruleNames, "throwing", "looping", "recursing")
+
+	tests := []struct {
+		name        string
+		concurrency int
+	}{
+		{name: "sequential", concurrency: 1},
+		{name: "parallel", concurrency: 4},
+		{name: "more workers than rules", concurrency: 64},
+	}
+
+	var want reportmanager.ReportManager
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			concurrency, ruleTimeout, runTimeout = tt.concurrency, 200*time.Millisecond, 0
+
+			// guarded like run.go does
+			rm := reportmanager.New()
+			var rmMu sync.Mutex
+			newRunConfig := func(rule string, schema map[string]any, ops []compiler.Operation) *compiler.RunConfig {
+				return &compiler.RunConfig{
+					ApiSchema:  schema,
+					Operations: ops,
+					Rule:       rule,
+					SetScore: func(category string, score float32) error {
+						rmMu.Lock()
+						defer rmMu.Unlock()
+						rm.SetScore(rule, reportmanager.Score{Category: category, Value: score})
+						return nil
+					},
+					Report: func(body *reportmanager.ReportDef) error {
+						rmMu.Lock()
+						defer rmMu.Unlock()
+						rm.PushReport(rule, *body)
+						return nil
+					},
+				}
+			}
+
+			results := runRulePool(cmp, ruleNames, programs, rules, schema, operations, newRunConfig)
+			rm.SortReports()
+
+			if len(results) != len(ruleNames) {
+				t.Fatalf("results = %d, want %d", len(results), len(ruleNames))
+			}
+			for _, rule := range ruleNames {
+				err := results[rule].err
+				switch rule {
+				case "throwing":
+					if !errors.Is(err, compiler.ErrExceptionInPluginCode) {
+						t.Errorf("%s error = %v, want %v", rule, err, compiler.ErrExceptionInPluginCode)
+					}
+				case "recursing":
+					// fails on the call stack limit long before the rule timeout
+					if !errors.Is(err, compiler.ErrExceptionInPluginCode) || !strings.Contains(err.Error(), "call stack") {
+						t.Errorf("%s error = %v, want a call stack overflow", rule, err)
+					}
+				case "looping":
+					if !errors.Is(err, errRuleTimeout) {
+						t.Errorf("%s error = %v, want %v", rule, err, errRuleTimeout)
+					}
+				default:
+					if err != nil {
+						t.Errorf("%s error = %v", rule, err)
+					}
+				}
+			}
+
+			for i := 0; i < 24; i++ {
+				rule := fmt.Sprintf("rule_%02d", i)
+				if got := len(rm[rule].Reports); got != len(operations) {
+					t.Errorf("%s reports = %d, want %d", rule, got, len(operations))
+				}
+				if got := rm[rule].Score.Value; got != float32(i) {
+					t.Errorf("%s score = %v, want %d", rule, got, i)
+				}
+			}
+			for i := 0; i < 4; i++ {
+				rule := fmt.Sprintf("mutating_%d", i)
+				if reports := rm[rule].Reports; len(reports) != 1 || reports[0].Message != "title pets" {
+					t.Errorf("%s reports = %v, want the unchanged title", rule, reports)
+				}
+			}
+			if schema["info"].(map[string]any)["title"] != "pets" {
+				t.Error("schema given to the pool is changed by the rules")
+			}
+
+			// aggregated reports and scores don't depend on the number of workers
+			if want == nil {
+				want = rm
+			} else if !reflect.DeepEqual(rm, want) {
+				t.Error("reports differ from the sequential run")
+			}
+		})
+	}
+}
@@ -1,5 +1,9 @@
 package reportmanager
 
+import (
+	"sort"
+)
+
 type Score struct {
 	Category string  `json:"category" toml:"category"`
 	Value    float32 `json:"value" toml:"value"`
@@ -10,11 +14,44 @@ type Headers struct {
 	Value string `json:"value" toml:"value"`
 }
 
+// severity levels of a report
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+	SeverityHint    = "hint"
+)
+
+// rank of the severity to compare them, higher is more severe
+// invalid severity gets -1
+func SeverityRank(severity string) int {
+	switch severity {
+	case SeverityError:
+		return 3
+	case SeverityWarning:
+		return 2
+	case SeverityInfo:
+		return 1
+	case SeverityHint:
+		return 0
+	}
+	return -1
+}
+
 type ReportDef struct {
-	// whether its a warning or error
-	Method   string         `json:"method,omitempty" toml:"method,omitempty"`
-	Path     string         `json:"path,omitempty" toml:"path,omitempty"`
-	Message  string         `json:"message" toml:"message"`
+	// http method of the operation, publish or subscribe for asyncapi
+	Method  string `json:"method,omitempty" toml:"method,omitempty"`
+	Path    string `json:"path,omitempty" toml:"path,omitempty"`
+	Message string `json:"message" toml:"message"`
+	// whether its an error, warning, info or hint
+	// rule's default severity is used when not given
+	Severity string `json:"severity,omitempty" toml:"severity,omitempty"`
+	// json pointer to the element in the schema given to rules like /paths/~1pets/get
+	Pointer string `json:"pointer,omitempty" toml:"pointer,omitempty"`
+	// source location resolved from pointer, file differs from schema for external refs
+	File     string         `json:"file,omitempty" toml:"file,omitempty"`
+	Line     int            `json:"line,omitempty" toml:"line,omitempty"`
+	Column   int            `json:"column,omitempty" toml:"column,omitempty"`
 	Headers  []Headers      `json:"headers,omitempty" toml:"headers,omitempty"`
 	Metadata map[string]any `json:"metadata,omitempty" toml:"metadata,omitempty"`
 }
@@ -24,6 +61,7 @@ type Report struct {
 	Reports []ReportDef `json:"reports,omitempty"`
 }
 
+// not safe for concurrent use, callers running rules concurrently guard it
 type ReportManager map[string]Report
 
 func New() ReportManager {
@@ -39,6 +77,48 @@ func (r ReportManager) PushReport(ruleName string, data ReportDef) {
 	}
 }
 
+// RemoveReports removes the reports for which remove returns true
+// returns the number of removed reports
+func (r ReportManager) RemoveReports(remove func(ruleName string, data ReportDef) bool) int {
+	removed := 0
+	for ruleName, val := range r {
+		reports := val.Reports[:0]
+		for _, report := range val.Reports {
+			if remove(ruleName, report) {
+				removed++
+				continue
+			}
+			reports = append(reports, report)
+		}
+		if len(reports) == 0 {
+			reports = nil
+		}
+		val.Reports = reports
+		r[ruleName] = val
+	}
+	return removed
+}
+
+// SortReports orders the reports of each rule by location and message
+// reports are pushed in the order rules emit them, which is not stable across runs
+func (r ReportManager) SortReports() {
+	for _, val := range r {
+		sort.SliceStable(val.Reports, func(i, j int) bool {
+			a, b := val.Reports[i], val.Reports[j]
+			if a.Path != b.Path {
+				return a.Path < b.Path
+			}
+			if a.Method != b.Method {
+				return a.Method < b.Method
+			}
+			if a.Pointer != b.Pointer {
+				return a.Pointer < b.Pointer
+			}
+			return a.Message < b.Message
+		})
+	}
+}
+
 func (r ReportManager) SetScore(ruleName string, score Score) {
 	if val, ok := r[ruleName]; ok {
 		val.Score = score
@@ -52,7 +132,19 @@ func (r ReportManager) GetTotalScore() []Score {
 	scoreN := make(map[string]int)
 	scoreSum := make(map[string]float32)
 
-	for _, report := range r {
+	// summing in a fixed order keeps the float result same across runs
+	rules := make([]string, 0, len(r))
+	for rule := range r {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+
+	for _, rule := range rules {
+		report := r[rule]
+		// rules with only reports and no score
+		if report.Score.Category == "" {
+			continue
+		}
 		scoreN[report.Score.Category] += 1
 		scoreSum[report.Score.Category] += report.Score.Value
 	}
@@ -61,6 +153,7 @@ func (r ReportManager) GetTotalScore() []Score {
 	for cat, score := range scoreSum {
 		finalScore = append(finalScore, Score{Category: cat, Value: score / float32(scoreN[cat])})
 	}
+	sort.Slice(finalScore, func(i, j int) bool { return finalScore[i].Category < finalScore[j].Category })
 
 	return finalScore
 }
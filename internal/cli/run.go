@@ -11,11 +11,15 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/1-platform/api-catalog/internal/cli/compiler"
+	"github.com/1-platform/api-catalog/internal/cli/compiler/modules"
 	"github.com/1-platform/api-catalog/internal/cli/filereader"
 	"github.com/1-platform/api-catalog/internal/cli/pluginmanager"
 	"github.com/1-platform/api-catalog/internal/cli/reportmanager"
+	"github.com/dop251/goja"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -23,12 +27,53 @@ import (
 type reportRuleMetrics struct {
 	TotalRules  int `json:"total_rules" toml:"total_rules"`
 	PassedRules int `json:"rules_passed" toml:"rules_passed"`
+	// rule -> time taken to run the rule in milliseconds
+	RuleTimings map[string]float64 `json:"rule_timings_ms,omitempty" toml:"rule_timings_ms,omitempty"`
+}
+
+// rule counts shown by cli and the exports
+// disabled rules are counted in total but are neither passed nor failed
+type ruleCounts struct {
+	Total    int
+	Passed   int
+	Failed   int
+	Disabled int
+}
+
+// metrics can be nil, then every enabled rule not in ruleErrors is passed
+func newRuleCounts(metrics *reportRuleMetrics, rules map[string]*pluginmanager.PluginRule, ruleErrors map[string]string) ruleCounts {
+	c := ruleCounts{Total: len(rules)}
+	for _, rule := range rules {
+		if rule.Disable {
+			c.Disabled++
+		}
+	}
+	if metrics != nil {
+		c.Total, c.Passed = metrics.TotalRules, metrics.PassedRules
+	} else {
+		c.Passed = c.Total - c.Disabled - len(ruleErrors)
+	}
+	if c.Failed = c.Total - c.Passed - c.Disabled; c.Failed < 0 {
+		c.Failed = 0
+	}
+	return c
 }
 
 // final report export data
 type reportExportData struct {
 	Metrics    *reportRuleMetrics           `json:"metrics" toml:"metrics"`
 	RuleReport *reportmanager.ReportManager `json:"reports" toml:"reports"`
+	Baseline   *baselineResult              `json:"baseline,omitempty" toml:"baseline,omitempty"`
+	// x-apic-ignore in schema and the reports they suppressed
+	Suppressions []*suppression `json:"suppressions,omitempty" toml:"suppressions,omitempty"`
+	// rest are not serialized but used by exporters like sarif
+	rules map[string]*pluginmanager.PluginRule
+	// rule -> exception thrown by the rule
+	ruleErrors map[string]string
+	// name of the junit test suite after apic. like openapi or diff
+	suite  string
+	title  string
+	schema string
 }
 
 // util
@@ -79,13 +124,46 @@ func bootUpChecks(fr *filereader.FileReader, logger *CliLogger) {
 	logger.Success("Builtin plugins successfully installed")
 }
 
-func runCommand(_cmd *cobra.Command, _args []string) {
+// marks the spec violations as errors and stops the run in abort mode
+func checkSpecViolations(logger *CliLogger, config ApiCatalogConfig, spec string, rule string, violations []reportmanager.ReportDef) {
+	for i := range violations {
+		violations[i].Severity = reportmanager.SeverityError
+	}
+	if len(violations) == 0 {
+		logger.Success(fmt.Sprintf("%s validation check passed", spec))
+		return
+	}
+
+	logger.Error(fmt.Sprintf("Failed to meet %s spec", spec))
+	if config.SpecValidation == specValidationAbort {
+		for _, v := range violations {
+			logger.Report(rule, v.Severity, v.Method, v.Pointer, v.Message)
+			logger.Divider()
+		}
+		log.Fatal(fmt.Sprintf("Aborting as schema doesn't meet %s spec", spec))
+	}
+}
+
+// result of running the rules over the schema
+type ruleRunResult struct {
+	config ApiCatalogConfig
+	fr     *filereader.FileReader
+	rm     reportmanager.ReportManager
+	rules  map[string]*pluginmanager.PluginRule
+	// rule -> exception thrown by the rule
+	ruleErrors   map[string]string
+	passedRules  int
+	ruleTimings  map[string]time.Duration
+	suppressions *suppressions
+	// dereferenced schema the rules ran on
+	schema map[string]any
+}
+
+// loads up the config, plugins and the schema then runs every rule over the schema
+func runRules(logger *CliLogger) *ruleRunResult {
 	// find config file and load up the config
 	var config ApiCatalogConfig
 
-	// setup cli logger
-	logger := NewCliLogger()
-
 	configExt := filepath.Ext(configFilePath)
 	if configExt == "" {
 		viper.AddConfigPath(configFilePath)
@@ -117,7 +195,24 @@ func runCommand(_cmd *cobra.Command, _args []string) {
 	}
 
 	// set up the js script compiler
-	cmp, err := compiler.New(logger)
+	cacheDir := ""
+	if !noCache {
+		cacheDir = apicCacheDir()
+	}
+	projectRoot, err := filepath.Abs(config.ProjectRoot)
+	if err != nil {
+		log.Fatal("Failed to find project root\n", err)
+	}
+	if httpMode != modules.HTTPModeLive && httpMode != modules.HTTPModeRecord && httpMode != modules.HTTPModeReplay {
+		log.Fatalf("Invalid http mode %s. Allowed values: live, record, replay", httpMode)
+	}
+	cmp, err := compiler.New(logger, cacheDir, modules.Config{
+		Root:         projectRoot,
+		Parser:       fr,
+		HTTPClient:   fr.HTTPClient(),
+		HTTPMode:     httpMode,
+		HTTPFixtures: httpFixtures,
+	})
 	if err != nil {
 		log.Fatal("Error in setting up compiler\n", err)
 	}
@@ -138,35 +233,140 @@ func runCommand(_cmd *cobra.Command, _args []string) {
 		log.Fatal(err)
 	}
 
-	var apiSchemaFile map[string]interface{}
-	raw, err := fr.ReadFileReturnRaw(apiSchemaURL, &apiSchemaFile)
-	if err != nil {
-		log.Fatal("Failed to read file\n", err)
+	if config.SpecValidation == "" {
+		config.SpecValidation = specValidationScore
+	}
+	if config.SpecValidation != specValidationScore && config.SpecValidation != specValidationAbort {
+		log.Fatal(fmt.Sprintf("Invalid spec_validation %s. Allowed values: score, abort", config.SpecValidation))
+	}
+	if err := config.Thresholds.validate(); err != nil {
+		log.Fatal("Error in config file\n", err)
 	}
-	logger.Completed("Read and parsed API schema file")
 
+	var apiSchemaFile map[string]interface{}
+	var schemaRefs map[string]string
+	var operations []compiler.Operation
+	// used to find file, line and column of a report
+	var sourceMap *filereader.SourceMap
 	rm := reportmanager.New()
 
-	// validation
+	// x-apic-ignore in schema
+	var schemaSuppressions *suppressions
+	// spec violations are reported once the schema is dereferenced
+	var specRule string
+	var specViolations []reportmanager.ReportDef
+	specChecked := 0
+
+	// fill the source location of a report from its json pointer
+	// then push it unless the rule is suppressed for the element
+	// rules report concurrently and suppressions keep count of the matched reports
+	// rmMu guards rm and suppressions while the rules run
+	var rmMu sync.Mutex
+	pushReport := func(rule string, report *reportmanager.ReportDef) {
+		rmMu.Lock()
+		defer rmMu.Unlock()
+
+		pointer := report.Pointer
+		// openapi rules report path and method that maps to the operation
+		if pointer == "" && apiType == "openapi" && report.Path != "" {
+			pointer = openAPIReportPointer(apiSchemaFile, report.Path, report.Method)
+		}
+		if pointer != "" && report.File == "" {
+			if file, pos, ok := sourceMap.Resolve(pointer); ok {
+				report.File, report.Line, report.Column = file, pos.Line, pos.Column
+			}
+		}
+		if schemaSuppressions.suppress(rule, pointer, *report) {
+			return
+		}
+		rm.PushReport(rule, *report)
+	}
+
+	// read and validation
 	switch apiType {
 	case "openapi":
-		if err := ValidateOpenAPI(raw, apiSchemaFile, logger); err != nil {
+		raw, err := fr.ReadFileReturnRaw(apiSchemaURL, &apiSchemaFile)
+		if err != nil {
+			log.Fatal("Failed to read file\n", err)
+		}
+		logger.Completed("Read and parsed API schema file")
+
+		doc, v3SchemaFile, err := ValidateOpenAPI(raw, apiSchemaFile, apiSchemaURL, fr, logger)
+		if err != nil {
 			log.Fatal("Failed to validate openapi schema\n", err)
 		}
-		logger.Success("OpenAPI validation check passed")
-		// iterate over rule
+
+		logger.Info("Validating by OpenAPI schema specs")
+		specRule = openAPISpecValidationRule
+		specViolations, specChecked = ValidateOpenAPISpec(doc)
+		checkSpecViolations(logger, config, "OpenAPI", specRule, specViolations)
+		operations = OpenAPIOperations(doc)
+
+		// rules get the v3 schema with all local and external refs resolved
+		if apiSchemaFile, schemaRefs, err = fr.Dereference(apiSchemaURL, v3SchemaFile); err != nil {
+			log.Fatal("Failed to resolve refs in openapi schema\n", err)
+		}
+		logger.Completed("Resolved API schema refs")
+		sourceMap = fr.NewSourceMap(apiSchemaURL, raw, schemaRefs)
+	case "graphql":
+		// SDL is not a json/yaml/toml document thus parsing is done by graphql validator
+		raw, err := fr.ReadIntoRawBytes(apiSchemaURL)
+		if err != nil {
+			log.Fatal("Failed to read file\n", err)
+		}
+		logger.Completed("Read API schema file")
+
+		if apiSchemaFile, err = ValidateGraphQL(raw, apiSchemaURL, logger); err != nil {
+			log.Fatal("Failed to validate graphql schema\n", err)
+		}
+		logger.Success("GraphQL validation check passed")
+	case "asyncapi":
+		raw, err := fr.ReadFileReturnRaw(apiSchemaURL, &apiSchemaFile)
+		if err != nil {
+			log.Fatal("Failed to read file\n", err)
+		}
+		logger.Completed("Read and parsed API schema file")
+		sourceMap = fr.NewSourceMap(apiSchemaURL, raw, nil)
+
+		specRule = asyncAPISpecValidationRule
+		if specViolations, specChecked, err = ValidateAsyncAPI(apiSchemaFile, logger); err != nil {
+			log.Fatal("Failed to validate asyncapi schema\n", err)
+		}
+		checkSpecViolations(logger, config, "AsyncAPI", specRule, specViolations)
+	case "grpc":
+		var err error
+		if apiSchemaFile, err = ValidateGRPC(fr, apiSchemaURL, logger); err != nil {
+			log.Fatal("Failed to validate grpc schema\n", err)
+		}
+		logger.Completed("Read and parsed API schema file")
+		logger.Success("Protobuf validation check passed")
 	default:
 		logger.Error(fmt.Sprintf("Error api type not supported: %s", apiType))
-		os.Exit(0)
+		os.Exit(exitError)
 	}
 
-	rulesPassedCounter := 0
-	totalRules := len(pManager.Rules)
-	for rule, opt := range pManager.Rules {
+	schemaSuppressions = newSuppressions(apiSchemaFile, sourceMap)
+
+	// violations are reported like any other rule, score is the percentage of valid elements
+	if specChecked > 0 {
+		for i := range specViolations {
+			pushReport(specRule, &specViolations[i])
+		}
+		rm.SetScore(specRule, reportmanager.Score{Category: "quality", Value: float32(specChecked-len(specViolations)) / float32(specChecked) * 100})
+	}
+
+	// rules are transpiled once, the programs can run on any runtime
+	var ruleNames []string
+	programs := make(map[string]*goja.Program)
+	for _, rule := range sortedRuleNames(pManager.Rules) {
+		opt := pManager.Rules[rule]
 		if opt.Disable {
 			logger.Info(fmt.Sprintf("%s has been disabled", rule))
 			continue
 		}
+		if missing := opt.MissingCapabilities(); len(missing) > 0 {
+			logger.Warn(fmt.Sprintf("%s needs capabilities that are not granted: %s. Grant them in apic.toml with rules.%s.grant", rule, strings.Join(missing, ", "), rule))
+		}
 
 		// read original code
 		rawCode, err := pManager.ReadPluginCode(opt.File)
@@ -179,69 +379,182 @@ func runCommand(_cmd *cobra.Command, _args []string) {
 		if err != nil {
 			log.Fatal("Failed to : ", err)
 		}
+		programs[rule] = code
+		ruleNames = append(ruleNames, rule)
+	}
 
-		// creating config for each rule because we also want rule name of each score and report setter
-		runCfg := &compiler.RunConfig{
-			Type:      apiType,
-			ApiSchema: apiSchemaFile,
-			SetScore: func(category string, score float32) {
+	// creating config for each rule because we also want rule name of each score and report setter
+	newRunConfig := func(rule string, schema map[string]any, ops []compiler.Operation) *compiler.RunConfig {
+		opt := pManager.Rules[rule]
+		return &compiler.RunConfig{
+			Type:       apiType,
+			ApiSchema:  schema,
+			SchemaRefs: schemaRefs,
+			Operations: ops,
+			Rule:       rule,
+			File:       opt.File,
+			// only the capabilities both declared by the rule and granted by user
+			Capabilities: opt.GrantedCapabilities(),
+			SetScore: func(category string, score float32) error {
 				// all other ones are invalid
 				if category != "performance" && category != "security" && category != "quality" {
-					logger.Error(fmt.Sprintf("%s gave invalid category - %s", rule, category))
-					os.Exit(0)
+					return fmt.Errorf("invalid score category - %s", category)
 				}
+				rmMu.Lock()
+				defer rmMu.Unlock()
 				rm.SetScore(rule, reportmanager.Score{Category: category, Value: score})
+				return nil
 			},
-			Report: func(body *reportmanager.ReportDef) {
+			Report: func(body *reportmanager.ReportDef) error {
 				if body.Message == "" {
-					logger.Error(fmt.Sprintf("%s didn't give message for report", rule))
-					os.Exit(0)
+					return errors.New("message is required for report")
+				}
+				if body.Severity == "" {
+					body.Severity = opt.Severity
 				}
-				rm.PushReport(rule, *body)
+				if reportmanager.SeverityRank(body.Severity) < 0 {
+					return fmt.Errorf("invalid report severity - %s", body.Severity)
+				}
+				pushReport(rule, body)
+				return nil
 			},
 		}
+	}
 
-		// execute the code
-		err = cmp.Run(code, runCfg, opt.Options)
-		if err != nil {
-			if errors.Is(err, compiler.ErrExceptionInPluginCode) {
+	results := runRulePool(cmp, ruleNames, programs, pManager.Rules, apiSchemaFile, operations, newRunConfig)
+
+	// output should not depend on the order rules finished in
+	rm.SortReports()
+
+	rulesPassedCounter := 0
+	ruleErrors := make(map[string]string)
+	ruleTimings := make(map[string]time.Duration, len(results))
+	for _, rule := range ruleNames {
+		result := results[rule]
+		ruleTimings[rule] = result.duration
+		if result.err != nil {
+			if errors.Is(result.err, compiler.ErrExceptionInPluginCode) {
 				logger.Error(fmt.Sprintf("%s threw an exception", rule))
-				logger.Error(err.Error())
+				logger.Error(result.err.Error())
+				ruleErrors[rule] = result.err.Error()
+				continue
+			} else if errors.Is(result.err, errRuleTimeout) {
+				// remaining rules keep running, the timed out one is a failed rule
+				logger.Error(fmt.Sprintf("%s failed: %s", rule, result.err))
+				ruleErrors[rule] = result.err.Error()
 				continue
 			} else {
-				log.Fatal("Failed to: ", err)
+				log.Fatal("Failed to: ", result.err)
 			}
 		}
-		logger.Info(fmt.Sprintf("%s check completed", rule))
+		logger.Info(fmt.Sprintf("%s check completed in %s", rule, result.duration.Round(time.Millisecond)))
 		rulesPassedCounter++
 	}
 
+	return &ruleRunResult{
+		config:       config,
+		fr:           fr,
+		rm:           rm,
+		rules:        pManager.Rules,
+		ruleErrors:   ruleErrors,
+		passedRules:  rulesPassedCounter,
+		ruleTimings:  ruleTimings,
+		suppressions: schemaSuppressions,
+		schema:       apiSchemaFile,
+	}
+}
+
+func runCommand(_cmd *cobra.Command, _args []string) {
+	// setup cli logger
+	logger := NewCliLogger()
+
+	res := runRules(logger)
+	rm := res.rm
+	totalRules := len(res.rules)
+
+	unusedSuppressions := res.suppressions.unused()
+
+	// only reports on changed operations are kept, scores are still of the whole schema
+	if sinceRef != "" {
+		if apiType != "openapi" {
+			log.Fatal("--since is only supported for openapi")
+		}
+		removed, err := applySince(res.fr, sinceRef, res.schema, rm, logger)
+		if err != nil {
+			log.Fatal("Failed to find changed operations\n", err)
+		}
+		logger.Info(fmt.Sprintf("%d reports on unchanged operations are skipped", removed))
+	}
+
+	// known reports are removed thus only new ones are reported and counted in thresholds
+	var baseline *baselineResult
+	if baselinePath != "" {
+		var err error
+		if baseline, err = applyBaseline(res.fr, baselinePath, rm); err != nil {
+			log.Fatal("Failed to read baseline\n", err)
+		}
+		logger.Info(fmt.Sprintf("%d reports are suppressed by baseline %s", baseline.Suppressed, baselinePath))
+	}
+
 	if exportReportPath != "" {
 		logger.Info(fmt.Sprintf("Exporting reports to %s", exportReportPath))
 		expData := reportExportData{
 			Metrics: &reportRuleMetrics{
 				TotalRules:  totalRules,
-				PassedRules: rulesPassedCounter,
+				PassedRules: res.passedRules,
+				RuleTimings: make(map[string]float64, len(res.ruleTimings)),
 			},
-			RuleReport: &rm,
+			RuleReport:   &rm,
+			Baseline:     baseline,
+			Suppressions: res.suppressions.list,
+			rules:        res.rules,
+			ruleErrors:   res.ruleErrors,
+			title:        res.config.Title,
+			suite:        apiType,
+			schema:       apiSchemaURL,
 		}
-		if err := fr.SaveFile(exportReportPath, &expData); err != nil {
+		for rule, d := range res.ruleTimings {
+			expData.Metrics.RuleTimings[rule] = float64(d.Microseconds()) / 1000
+		}
+		if err := res.fr.SaveFile(exportReportPath, &expData); err != nil {
 			log.Fatal("Failed to export report\n", err)
 		}
 	}
 
-	logger.RuleMetrics(rulesPassedCounter, totalRules)
+	logger.RuleMetrics(newRuleCounts(&reportRuleMetrics{TotalRules: totalRules, PassedRules: res.passedRules}, res.rules, res.ruleErrors))
 
 	logger.Title("Reports")
-	for rule, r := range rm {
-		for _, report := range r.Reports {
-			logger.Report(rule, report.Method, report.Path, report.Message)
+	for _, rule := range sortedReportRules(rm) {
+		for _, report := range rm[rule].Reports {
+			logger.Report(rule, report.Severity, report.Method, report.Path, report.Message)
+			logger.Divider()
+		}
+	}
+
+	for _, sup := range unusedSuppressions {
+		location := sup.Pointer
+		if sup.File != "" {
+			location = fmt.Sprintf("%s:%d:%d", sup.File, sup.Line, sup.Column)
+		}
+		logger.Warn(fmt.Sprintf("%s of %s at %s doesn't suppress any report", ignoreExtension, sup.Rule, location))
+	}
+
+	if baseline != nil && len(baseline.Stale) > 0 {
+		logger.Title("Stale Baseline Entries")
+		for _, entry := range baseline.Stale {
+			logger.Report(entry.Rule, entry.Severity, entry.Method, entry.Path, entry.Message)
 			logger.Divider()
 		}
+		logger.Warn(fmt.Sprintf("%d baseline entries are not reported anymore. Run apic baseline to update it", len(baseline.Stale)))
 	}
 
 	logger.Title("Score Card")
 	scores := rm.GetTotalScore()
 	logger.ScoreCard(scores)
 
+	code, reasons := res.config.Thresholds.check(scores, rm, len(res.ruleErrors))
+	for _, reason := range reasons {
+		logger.Error(fmt.Sprintf("Threshold failed: %s", reason))
+	}
+	os.Exit(code)
 }
@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/1-platform/api-catalog/internal/cli/compiler/modules"
+	"github.com/spf13/viper"
+)
+
+// runs the builtin rules of apiType and the config over the schema like apic run does
+func runTestRules(t *testing.T, api string, schemaName string, schema string, config string) *ruleRunResult {
+	t.Helper()
+	dir := t.TempDir()
+	schemaFile := filepath.Join(dir, schemaName)
+	configFile := filepath.Join(dir, "apic.toml")
+	if err := os.WriteFile(schemaFile, []byte(schema), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(configFile, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	prev := []any{apiType, apiSchemaURL, configFilePath, version, noCache, httpMode, concurrency, ruleTimeout, runTimeout}
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		apiType, apiSchemaURL, configFilePath = prev[0].(string), prev[1].(string), prev[2].(string)
+		version, noCache, httpMode = prev[3].(string), prev[4].(bool), prev[5].(string)
+		concurrency, ruleTimeout, runTimeout = prev[6].(int), prev[7].(time.Duration), prev[8].(time.Duration)
+		viper.Reset()
+		os.Chdir(cwd)
+	})
+
+	// builtin plugins are read from the repo in development
+	if err := os.Chdir(filepath.Join("..", "..")); err != nil {
+		t.Fatal(err)
+	}
+	viper.Reset()
+	apiType, apiSchemaURL, configFilePath = api, schemaFile, configFile
+	version, noCache, httpMode = "development", true, modules.HTTPModeLive
+	concurrency, ruleTimeout, runTimeout = 0, time.Minute, 0
+
+	return runRules(NewCliLogger())
+}
+
+func TestRunSuppressions(t *testing.T) {
+	const spec = `openapi: 3.0.3
+info:
+  title: pets
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      x-apic-ignore: [url_plural_checker]
+      parameters:
+        - name: pet_status
+          in: query
+          x-apic-ignore: [schema_case_checker]
+          schema:
+            type: string
+      responses:
+        "200":
+          description: ok
+components:
+  schemas:
+    Pet:
+      x-apic-ignore: [schema_case_checker]
+      type: object
+      properties:
+        pet_name:
+          type: string
+    Tag:
+      type: object
+      properties:
+        tag_name:
+          type: string
+`
+	res := runTestRules(t, "openapi", "openapi.yaml", spec, "")
+
+	reports := res.rm["schema_case_checker"].Reports
+	if len(reports) != 1 || reports[0].Message != "Invalid casing for tag_name of schema Tag" {
+		t.Fatalf("schema_case_checker reports = %v, want only the one of Tag", reports)
+	}
+	if reports[0].Line != 29 || reports[0].Column != 9 {
+		t.Errorf("tag_name report is at %d:%d, want 29:9", reports[0].Line, reports[0].Column)
+	}
+
+	for _, sup := range res.suppressions.list {
+		if sup.Rule == "schema_case_checker" && len(sup.Reports) != 1 {
+			t.Errorf("suppression at %s suppressed %d reports, want 1", sup.Pointer, len(sup.Reports))
+		}
+	}
+	if unused := res.suppressions.unused(); len(unused) != 1 || unused[0].Rule != "url_plural_checker" {
+		t.Errorf("unused suppressions = %v, want only the one of url_plural_checker", unused)
+	}
+}
+
+func TestRunBuiltinRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		api    string
+		file   string
+		schema string
+		// pointers of the reports of each rule
+		want map[string][]string
+	}{
+		{
+			name:   "graphql",
+			api:    "graphql",
+			file:   "schema.graphql",
+			schema: "type Query {\n  \"pets by status\"\n  pets(pet_status: String): [pet]\n}\n\n\"a pet\"\ntype pet {\n  \"name\"\n  pet_name: String\n}\n",
+			want: map[string][]string{
+				"field_case_checker": {"/types/0/fields/0/args/0", "/types/1/fields/0"},
+				"type_case_checker":  {"/types/1"},
+			},
+		},
+		{
+			name:   "asyncapi",
+			api:    "asyncapi",
+			file:   "asyncapi.yaml",
+			schema: "asyncapi: 2.6.0\ninfo:\n  title: pets\n  version: 1.0.0\nchannels:\n  pet/Created:\n    publish:\n      operationId: petCreated\n      message:\n        oneOf:\n          - payload: {type: object}\n          - name: legacy\n",
+			want: map[string][]string{
+				"channel_case_checker":  {"/channels/pet~1Created"},
+				"message_payload_check": {"/channels/pet~1Created/publish/message/oneOf/1"},
+			},
+		},
+		{
+			name:   "asyncapi without channels",
+			api:    "asyncapi",
+			file:   "asyncapi.yaml",
+			schema: "asyncapi: 2.6.0\ninfo:\n  title: pets\n  version: 1.0.0\nchannels: {}\n",
+		},
+		{
+			name:   "grpc",
+			api:    "grpc",
+			file:   "pets.proto",
+			schema: "syntax = \"proto3\";\npackage pets;\n\nservice pet_service {\n  rpc ListPets(ListPetsRequest) returns (Pets);\n}\n\nmessage ListPetsRequest {\n  string petStatus = 1;\n}\n\nmessage Pets {}\n",
+			want: map[string][]string{
+				"message_case_checker":  {"/messages/0/fields/0"},
+				"package_version_check": {"/files/0"},
+				"rpc_message_naming":    {"/services/0/methods/0"},
+				"service_case_checker":  {"/services/0"},
+			},
+		},
+		{
+			name:   "grpc without services",
+			api:    "grpc",
+			file:   "pets.proto",
+			schema: "syntax = \"proto3\";\npackage pets.v1;\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := runTestRules(t, tt.api, tt.file, tt.schema, "")
+			for rule, report := range res.rm {
+				if math.IsNaN(float64(report.Score.Value)) {
+					t.Errorf("%s score is NaN", rule)
+				}
+				var pointers []string
+				for _, r := range report.Reports {
+					pointers = append(pointers, r.Pointer)
+				}
+				if !reflect.DeepEqual(pointers, tt.want[rule]) {
+					t.Errorf("%s report pointers = %v, want %v", rule, pointers, tt.want[rule])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"sort"
+
+	"github.com/1-platform/api-catalog/internal/cli/reportmanager"
+	"github.com/goccy/go-json"
+)
+
+// minimal SARIF v2.1.0 log structure
+// REF: https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string              `json:"id"`
+	Name                 string              `json:"name"`
+	ShortDescription     sarifMessage        `json:"shortDescription"`
+	DefaultConfiguration *sarifConfiguration `json:"defaultConfiguration,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string            `json:"ruleId"`
+	RuleIndex  int               `json:"ruleIndex"`
+	Level      string            `json:"level"`
+	Message    sarifMessage      `json:"message"`
+	Locations  []sarifLocation   `json:"locations"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
+
+// sarif has no hint level thus both info and hint are notes
+func sarifLevel(severity string) string {
+	switch severity {
+	case reportmanager.SeverityError:
+		return "error"
+	case reportmanager.SeverityWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+func (e *reportExportData) MarshalSARIF() ([]byte, error) {
+	// rules with reports are included even if they are not plugins like spec validation
+	ruleSet := make(map[string]struct{}, len(e.rules))
+	for rule := range e.rules {
+		ruleSet[rule] = struct{}{}
+	}
+	for rule := range *e.RuleReport {
+		ruleSet[rule] = struct{}{}
+	}
+	ruleNames := make([]string, 0, len(ruleSet))
+	for rule := range ruleSet {
+		ruleNames = append(ruleNames, rule)
+	}
+	sort.Strings(ruleNames)
+
+	driver := sarifDriver{
+		Name:           "apic",
+		Version:        version,
+		InformationURI: "https://github.com/1-Platform/api-catalog",
+		Rules:          []sarifRule{},
+	}
+	results := []sarifResult{}
+	for i, rule := range ruleNames {
+		sRule := sarifRule{ID: rule, Name: rule, ShortDescription: sarifMessage{Text: rule}}
+		if opt, ok := e.rules[rule]; ok {
+			sRule.DefaultConfiguration = &sarifConfiguration{Level: sarifLevel(opt.Severity)}
+		}
+		driver.Rules = append(driver.Rules, sRule)
+
+		for _, report := range (*e.RuleReport)[rule].Reports {
+			location := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: e.schema}}
+			if report.File != "" {
+				location.ArtifactLocation.URI = report.File
+			}
+			if report.Line > 0 {
+				location.Region = &sarifRegion{StartLine: report.Line, StartColumn: report.Column}
+			}
+
+			result := sarifResult{
+				RuleID:    rule,
+				RuleIndex: i,
+				Level:     sarifLevel(report.Severity),
+				Message:   sarifMessage{Text: report.Message},
+				Locations: []sarifLocation{{PhysicalLocation: location}},
+			}
+			if report.Path != "" || report.Method != "" {
+				result.Properties = map[string]string{"path": report.Path, "method": report.Method}
+			}
+			results = append(results, result)
+		}
+	}
+
+	return json.MarshalIndent(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}, "", "  ")
+}
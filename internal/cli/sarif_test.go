@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/1-platform/api-catalog/internal/cli/reportmanager"
+	"github.com/goccy/go-json"
+)
+
+func TestSarifLevel(t *testing.T) {
+	tests := map[string]string{
+		reportmanager.SeverityError:   "error",
+		reportmanager.SeverityWarning: "warning",
+		reportmanager.SeverityInfo:    "note",
+		reportmanager.SeverityHint:    "note",
+		"":                            "note",
+	}
+	for severity, want := range tests {
+		if got := sarifLevel(severity); got != want {
+			t.Errorf("sarifLevel(%q) = %s, want %s", severity, got, want)
+		}
+	}
+}
+
+func TestMarshalSARIF(t *testing.T) {
+	out, err := testExportData().MarshalSARIF()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got sarifLog
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Version != "2.1.0" || len(got.Runs) != 1 {
+		t.Fatalf("version = %s with %d runs, want 2.1.0 with 1 run", got.Version, len(got.Runs))
+	}
+	run := got.Runs[0]
+
+	// plugin rules and the rules that only have reports, sorted
+	var ruleIDs []string
+	for _, rule := range run.Tool.Driver.Rules {
+		ruleIDs = append(ruleIDs, rule.ID)
+	}
+	wantRules := []string{"broken_rule", "openapi_spec_validation", "schema_case_checker", "status_code_check", "url_case_checker"}
+	if !reflect.DeepEqual(ruleIDs, wantRules) {
+		t.Errorf("rules = %v, want %v", ruleIDs, wantRules)
+	}
+	if conf := run.Tool.Driver.Rules[4].DefaultConfiguration; conf == nil || conf.Level != "warning" {
+		t.Errorf("url_case_checker default configuration = %v, want warning", conf)
+	}
+	if conf := run.Tool.Driver.Rules[1].DefaultConfiguration; conf != nil {
+		t.Errorf("openapi_spec_validation default configuration = %v, want none as it isn't a plugin", conf)
+	}
+
+	wantResults := []sarifResult{
+		{
+			RuleID: "openapi_spec_validation", RuleIndex: 1, Level: "error",
+			Message:   sarifMessage{Text: "invalid schema"},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: "openapi.yaml"}}}},
+		},
+		{
+			RuleID: "url_case_checker", RuleIndex: 4, Level: "warning",
+			Message: sarifMessage{Text: "url is not kebabcase"},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: "openapi.yaml"},
+				Region:           &sarifRegion{StartLine: 7, StartColumn: 5},
+			}}},
+			Properties: map[string]string{"path": "/Pets", "method": "get"},
+		},
+		{
+			RuleID: "url_case_checker", RuleIndex: 4, Level: "note",
+			Message:   sarifMessage{Text: "url is too long"},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: "openapi.yaml"}}}},
+		},
+	}
+	if !reflect.DeepEqual(run.Results, wantResults) {
+		t.Errorf("results = %+v, want %+v", run.Results, wantResults)
+	}
+}
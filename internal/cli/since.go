@@ -0,0 +1,236 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/1-platform/api-catalog/internal/cli/filereader"
+	"github.com/1-platform/api-catalog/internal/cli/reportmanager"
+	"github.com/goccy/go-json"
+)
+
+// file reader of the base schema of --since
+// a spec file or url is read as it is, otherwise local files are read at the git ref
+func sinceFileReader(fr *filereader.FileReader, since string, schemaLocation string) (*filereader.FileReader, string, error) {
+	if u, err := url.ParseRequestURI(since); err == nil && u.Scheme != "" {
+		return fr, since, nil
+	}
+	if _, err := os.Stat(since); err == nil {
+		return fr, since, nil
+	}
+
+	if u, err := url.ParseRequestURI(schemaLocation); err == nil && u.Scheme != "" {
+		return nil, "", fmt.Errorf("git ref %s needs a local schema file", since)
+	}
+	// external refs are read at the same ref as the schema, remote ones as they are
+	gitFR := fr.WithReadFunc(func(location string) ([]byte, error) {
+		u, err := filereader.LocationURL(location)
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme != "file" {
+			return fr.ReadIntoRawBytes(location)
+		}
+		return readGitFile(since, u.Path)
+	})
+	return gitFR, schemaLocation, nil
+}
+
+func readGitFile(ref string, file string) ([]byte, error) {
+	if abs, err := filepath.Abs(file); err == nil {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, abs); err == nil {
+				file = rel
+			}
+		}
+	}
+	// ./ makes the path relative to current dir instead of repo root
+	out, err := exec.Command("git", "show", fmt.Sprintf("%s:./%s", ref, filepath.ToSlash(filepath.Clean(file)))).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("failed to read %s at %s: %s", file, ref, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// operations of a dereferenced openapi v3 schema with the path level parameters
+// keyed by normalized path and lowercase method
+func schemaOperations(schema map[string]any) map[string]map[string]any {
+	operations := make(map[string]map[string]any)
+	paths, _ := schema["paths"].(map[string]any)
+	for path, item := range paths {
+		pathItem, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		for _, method := range []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"} {
+			op, ok := pathItem[method]
+			if !ok {
+				continue
+			}
+			operations[normalizePathTemplate(path)+" "+method] = map[string]any{
+				"parameters": pathItem["parameters"],
+				"operation":  op,
+			}
+		}
+	}
+	return operations
+}
+
+// content hash of dereferenced schema nodes
+// a node shared by refs is hashed once instead of at every place it's used
+type schemaHasher map[uintptr][]byte
+
+func (h schemaHasher) hash(node any) ([]byte, error) {
+	switch val := node.(type) {
+	case map[string]any:
+		key := reflect.ValueOf(val).Pointer()
+		if sum, ok := h[key]; ok {
+			return sum, nil
+		}
+		sha := sha256.New()
+		sha.Write([]byte("{"))
+		for _, k := range sortedKeys(val) {
+			child, err := h.hash(val[k])
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(sha, "%q:%x,", k, child)
+		}
+		h[key] = sha.Sum(nil)
+		return h[key], nil
+	case []any:
+		sha := sha256.New()
+		sha.Write([]byte("["))
+		for _, v := range val {
+			child, err := h.hash(v)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(sha, "%x,", child)
+		}
+		return sha.Sum(nil), nil
+	default:
+		raw, err := json.Marshal(val)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(raw)
+		return sum[:], nil
+	}
+}
+
+// operations that are added or changed in head compared to base
+// both are dereferenced thus changes in referenced schemas are found
+func changedOperations(base map[string]any, head map[string]any) (map[string]bool, error) {
+	h := make(schemaHasher)
+	baseOps := make(map[string][]byte)
+	for key, op := range schemaOperations(base) {
+		sum, err := h.hash(op)
+		if err != nil {
+			return nil, err
+		}
+		baseOps[key] = sum
+	}
+
+	changed := make(map[string]bool)
+	for key, op := range schemaOperations(head) {
+		sum, err := h.hash(op)
+		if err != nil {
+			return nil, err
+		}
+		if baseSum, ok := baseOps[key]; !ok || !bytes.Equal(baseSum, sum) {
+			changed[key] = true
+		}
+	}
+	return changed, nil
+}
+
+// path and methods of a report, from the pointer if rule didn't give them
+func reportOperation(report reportmanager.ReportDef) (string, []string) {
+	path, method := report.Path, report.Method
+	if path == "" && strings.HasPrefix(report.Pointer, "/paths/") {
+		tokens := strings.Split(report.Pointer, "/")
+		path = strings.ReplaceAll(strings.ReplaceAll(tokens[2], "~1", "/"), "~0", "~")
+		if len(tokens) > 3 {
+			method = tokens[3]
+		}
+	}
+
+	var methods []string
+	for _, m := range strings.Split(method, ",") {
+		if m = strings.ToLower(strings.TrimSpace(m)); m != "" {
+			methods = append(methods, m)
+		}
+	}
+	return path, methods
+}
+
+// removes the reports not on changed operations
+// reports on a path without method are kept if any operation of the path changed
+func filterReportsSince(rm reportmanager.ReportManager, changed map[string]bool) int {
+	changedPaths := make(map[string]bool)
+	for key := range changed {
+		path, _, _ := strings.Cut(key, " ")
+		changedPaths[path] = true
+	}
+
+	return rm.RemoveReports(func(_ string, report reportmanager.ReportDef) bool {
+		path, methods := reportOperation(report)
+		if path == "" {
+			return true
+		}
+		path = normalizePathTemplate(path)
+		if len(methods) == 0 {
+			return !changedPaths[path]
+		}
+		for _, method := range methods {
+			if changed[path+" "+method] {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// reads the base schema of --since and removes reports on unchanged operations
+// schema is the dereferenced v3 schema the rules ran on
+func applySince(fr *filereader.FileReader, since string, schema map[string]any, rm reportmanager.ReportManager, logger *CliLogger) (int, error) {
+	baseFR, baseLocation, err := sinceFileReader(fr, since, apiSchemaURL)
+	if err != nil {
+		return 0, err
+	}
+
+	var baseSchemaFile map[string]any
+	raw, err := baseFR.ReadFileReturnRaw(baseLocation, &baseSchemaFile)
+	if err != nil {
+		return 0, err
+	}
+
+	// refs of base are resolved from its own location with the same reader
+	_, v3SchemaFile, err := ValidateOpenAPI(raw, baseSchemaFile, baseLocation, baseFR, logger)
+	if err != nil {
+		return 0, err
+	}
+	baseSchema, _, err := baseFR.Dereference(baseLocation, v3SchemaFile)
+	if err != nil {
+		return 0, err
+	}
+
+	changed, err := changedOperations(baseSchema, schema)
+	if err != nil {
+		return 0, err
+	}
+	logger.Info(fmt.Sprintf("%d operations changed since %s", len(changed), since))
+
+	return filterReportsSince(rm, changed), nil
+}
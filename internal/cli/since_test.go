@@ -0,0 +1,251 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/1-platform/api-catalog/internal/cli/filereader"
+	"github.com/1-platform/api-catalog/internal/cli/reportmanager"
+)
+
+// writes the files relative to dir
+func writeSpecFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func dereferencedSchema(t *testing.T, fr *filereader.FileReader, location string) map[string]any {
+	t.Helper()
+	var doc map[string]any
+	if _, err := fr.ReadFileReturnRaw(location, &doc); err != nil {
+		t.Fatal(err)
+	}
+	schema, _, err := fr.Dereference(location, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+const sincePets = `openapi: 3.0.3
+info:
+  title: pets
+  version: 1.0.0
+paths:
+  /pets/{id}:
+    parameters:
+      - {name: id, in: path, required: true, schema: {type: string}}
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Pet"
+  /stores:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Store"
+  /owners:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Owner"
+components:
+  schemas:
+    Tag:
+      type: string
+    Pet:
+      type: object
+      properties:
+        tag: {$ref: "#/components/schemas/Tag"}
+    Store:
+      type: object
+      properties:
+        pets:
+          type: array
+          items: {$ref: "#/components/schemas/Pet"}
+    Owner:
+      type: object
+      properties:
+        name: {type: string}
+`
+
+func TestChangedOperations(t *testing.T) {
+	fr, err := filereader.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want []string
+	}{
+		{name: "no change"},
+		{name: "nested shared component", from: "Tag:\n      type: string", to: "Tag:\n      type: integer", want: []string{"/pets/{} get", "/stores get"}},
+		{name: "component of one operation", from: "name: {type: string}", to: "name: {type: integer}", want: []string{"/owners get"}},
+		{name: "path parameter", from: "required: true, schema: {type: string}", to: "required: true, schema: {type: integer}", want: []string{"/pets/{} get"}},
+		{name: "renamed path parameter", from: "/pets/{id}", to: "/pets/{petId}"},
+		{name: "added operation", from: "  /owners:\n    get:", to: "  /owners:\n    post:\n      responses:\n        \"201\": {description: ok}\n    get:", want: []string{"/owners post"}},
+		{name: "unused component", from: "Owner:", to: "Unused:\n      type: string\n    Owner:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			head := sincePets
+			if tt.from != "" {
+				head = replaceOnce(t, sincePets, tt.from, tt.to)
+			}
+			writeSpecFiles(t, dir, map[string]string{"base.yaml": sincePets, "head.yaml": head})
+
+			changed, err := changedOperations(dereferencedSchema(t, fr, filepath.Join(dir, "base.yaml")), dereferencedSchema(t, fr, filepath.Join(dir, "head.yaml")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for key := range changed {
+				got = append(got, key)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changedOperations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func replaceOnce(t *testing.T, s string, from string, to string) string {
+	t.Helper()
+	if !strings.Contains(s, from) {
+		t.Fatalf("%q is not in the spec", from)
+	}
+	return strings.Replace(s, from, to, 1)
+}
+
+func TestApplySinceGitRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=apic", "-c", "user.email=apic@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s", args, out)
+		}
+	}
+
+	const spec = `openapi: 3.0.3
+info:
+  title: pets
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: "./schemas/pet.yaml#/Pet"
+  /stores:
+    get:
+      responses:
+        "200": {description: ok}
+`
+	writeSpecFiles(t, dir, map[string]string{
+		"openapi.yaml":     spec,
+		"schemas/pet.yaml": "Pet:\n  type: object\n",
+	})
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "base")
+
+	// the referenced file is renamed and changed, base must still read the old one at the ref
+	if err := os.Remove(filepath.Join(dir, "schemas", "pet.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	writeSpecFiles(t, dir, map[string]string{
+		"openapi.yaml":        replaceOnce(t, spec, "./schemas/pet.yaml", "./schemas/animal.yaml") + "  /cats:\n    get:\n      responses:\n        \"200\": {description: ok}\n",
+		"schemas/animal.yaml": "Pet:\n  type: object\n  required: [name]\n",
+	})
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prevSchemaURL := apiSchemaURL
+	t.Cleanup(func() {
+		apiSchemaURL = prevSchemaURL
+		os.Chdir(cwd)
+	})
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	apiSchemaURL = "openapi.yaml"
+
+	fr, err := filereader.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rm := reportmanager.New()
+	for _, report := range []reportmanager.ReportDef{
+		{Path: "/pets", Method: "get", Message: "changed ref"},
+		{Path: "/stores", Method: "GET", Message: "unchanged"},
+		{Path: "/cats", Method: "get", Message: "added"},
+		{Pointer: "/paths/~1cats/get", Message: "added by pointer"},
+		{Path: "/pets", Message: "path of changed operation"},
+		{Message: "not on an operation"},
+	} {
+		rm.PushReport("rule", report)
+	}
+
+	removed, err := applySince(fr, "HEAD", dereferencedSchema(t, fr, "openapi.yaml"), rm, NewCliLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 2 {
+		t.Errorf("removed = %d, want 2", removed)
+	}
+	var got []string
+	for _, report := range rm["rule"].Reports {
+		got = append(got, report.Message)
+	}
+	want := []string{"changed ref", "added", "added by pointer", "path of changed operation"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("kept reports = %v, want %v", got, want)
+	}
+
+	if _, err := applySince(fr, "missing-ref", nil, reportmanager.New(), NewCliLogger()); err == nil {
+		t.Error("applySince() with an unknown ref must fail")
+	}
+}
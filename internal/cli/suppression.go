@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/1-platform/api-catalog/internal/cli/filereader"
+	"github.com/1-platform/api-catalog/internal/cli/reportmanager"
+)
+
+// schema extension to suppress rules on an element and its children
+// example: x-apic-ignore: [url_plural_checker]
+const ignoreExtension = "x-apic-ignore"
+
+// a rule suppressed by x-apic-ignore and the reports it suppressed
+type suppression struct {
+	Rule string `json:"rule" toml:"rule"`
+	// json pointer of the element having x-apic-ignore
+	Pointer string `json:"pointer" toml:"pointer"`
+	File    string `json:"file,omitempty" toml:"file,omitempty"`
+	Line    int    `json:"line,omitempty" toml:"line,omitempty"`
+	Column  int    `json:"column,omitempty" toml:"column,omitempty"`
+	// no report matched the suppression
+	Unused  bool                      `json:"unused" toml:"unused"`
+	Reports []reportmanager.ReportDef `json:"reports,omitempty" toml:"reports,omitempty"`
+}
+
+// x-apic-ignore of the schema
+type suppressions struct {
+	list []*suppression
+	// dereferenced schema, reports are matched by walking it along their pointer
+	schema map[string]any
+	// node having x-apic-ignore -> its suppressions
+	byNode map[uintptr][]*suppression
+}
+
+func ignoredRules(val any) []string {
+	switch v := val.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var rules []string
+		for _, rule := range v {
+			if r, ok := rule.(string); ok {
+				rules = append(rules, r)
+			}
+		}
+		return rules
+	}
+	return nil
+}
+
+// finds every x-apic-ignore in dereferenced schema
+// nodes shared by refs are walked once, suppressions from the same source location are merged
+func newSuppressions(schema map[string]any, sourceMap *filereader.SourceMap) *suppressions {
+	s := &suppressions{schema: schema, byNode: make(map[uintptr][]*suppression)}
+	bySource := make(map[string]*suppression)
+	visited := make(map[uintptr]bool)
+
+	var walk func(node any, pointer string)
+	walk = func(node any, pointer string) {
+		switch val := node.(type) {
+		case map[string]any:
+			node := reflect.ValueOf(val).Pointer()
+			if visited[node] {
+				return
+			}
+			visited[node] = true
+
+			if ignore, ok := val[ignoreExtension]; ok {
+				file, pos, located := sourceMap.Resolve(pointer + "/" + ignoreExtension)
+				for _, rule := range ignoredRules(ignore) {
+					key := fmt.Sprintf("%s:%s", rule, pointer)
+					if located {
+						key = fmt.Sprintf("%s:%s:%d:%d", rule, file, pos.Line, pos.Column)
+					}
+					sup, ok := bySource[key]
+					if !ok {
+						sup = &suppression{Rule: rule, Pointer: pointer}
+						if located {
+							sup.File, sup.Line, sup.Column = file, pos.Line, pos.Column
+						}
+						bySource[key] = sup
+						s.list = append(s.list, sup)
+					}
+					s.byNode[node] = append(s.byNode[node], sup)
+				}
+			}
+			// walk in order thus the pointer of a suppression is stable
+			for _, key := range sortedKeys(val) {
+				if key != ignoreExtension {
+					walk(val[key], pointer+"/"+filereader.EscapePointerToken(key))
+				}
+			}
+		case []any:
+			for i, child := range val {
+				walk(child, fmt.Sprintf("%s/%d", pointer, i))
+			}
+		}
+	}
+	walk(schema, "")
+
+	sort.SliceStable(s.list, func(i, j int) bool { return s.list[i].Pointer < s.list[j].Pointer })
+	return s
+}
+
+// checks whether report at pointer is suppressed, suppressed reports are recorded
+// the schema is walked along the pointer thus a shared node suppresses at every place it's used
+func (s *suppressions) suppress(rule string, pointer string, report reportmanager.ReportDef) bool {
+	if s == nil || len(s.list) == 0 {
+		return false
+	}
+
+	var tokens []string
+	if pointer != "" {
+		tokens = strings.Split(pointer, "/")[1:]
+	}
+	var node any = s.schema
+	for i := 0; ; i++ {
+		if val, ok := node.(map[string]any); ok {
+			for _, sup := range s.byNode[reflect.ValueOf(val).Pointer()] {
+				if sup.Rule == rule {
+					sup.Reports = append(sup.Reports, report)
+					return true
+				}
+			}
+		}
+		if i == len(tokens) {
+			return false
+		}
+
+		token := filereader.UnescapePointerToken(tokens[i])
+		switch val := node.(type) {
+		case map[string]any:
+			node = val[token]
+		case []any:
+			idx, err := strconv.Atoi(token)
+			if err != nil || idx < 0 || idx >= len(val) {
+				return false
+			}
+			node = val[idx]
+		default:
+			return false
+		}
+	}
+}
+
+// marks the suppressions that didn't match any report
+func (s *suppressions) unused() []*suppression {
+	if s == nil {
+		return nil
+	}
+	var unused []*suppression
+	for _, sup := range s.list {
+		sup.Unused = len(sup.Reports) == 0
+		if sup.Unused {
+			unused = append(unused, sup)
+		}
+	}
+	return unused
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/1-platform/api-catalog/internal/cli/filereader"
+	"github.com/1-platform/api-catalog/internal/cli/reportmanager"
+)
+
+const suppressionSpec = `openapi: 3.0.3
+info:
+  title: pets
+  version: 1.0.0
+paths:
+  /pets:
+    x-apic-ignore: url_plural_checker
+    get:
+      x-apic-ignore: [status_code_check, body_in_get_req]
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Pet"
+  /stores:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Pet"
+components:
+  schemas:
+    Pet:
+      x-apic-ignore: [schema_case_checker]
+      type: object
+      properties:
+        pet_name:
+          type: string
+        tags:
+          type: array
+          items:
+            $ref: "#/components/schemas/Pet"
+`
+
+func TestSuppressions(t *testing.T) {
+	location := filepath.Join(t.TempDir(), "openapi.yaml")
+	if err := os.WriteFile(location, []byte(suppressionSpec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fr, err := filereader.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	raw, err := fr.ReadFileReturnRaw(location, &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, refs, err := fr.Dereference(location, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const storeSchema = "/paths/~1stores/get/responses/200/content/application~1json/schema"
+	tests := []struct {
+		name     string
+		rule     string
+		pointer  string
+		suppress bool
+	}{
+		{name: "path", rule: "url_plural_checker", pointer: "/paths/~1pets", suppress: true},
+		{name: "operation of path", rule: "url_plural_checker", pointer: "/paths/~1pets/get", suppress: true},
+		{name: "operation", rule: "body_in_get_req", pointer: "/paths/~1pets/get", suppress: true},
+		{name: "child of operation", rule: "status_code_check", pointer: "/paths/~1pets/get/responses/200", suppress: true},
+		{name: "other path", rule: "url_plural_checker", pointer: "/paths/~1stores"},
+		{name: "other rule", rule: "url_case_checker", pointer: "/paths/~1pets"},
+		{name: "parent of suppression", rule: "body_in_get_req", pointer: "/paths/~1pets"},
+		{name: "component", rule: "schema_case_checker", pointer: "/components/schemas/Pet/properties/pet_name", suppress: true},
+		{name: "shared component in another operation", rule: "schema_case_checker", pointer: storeSchema + "/properties/pet_name", suppress: true},
+		{name: "circular component", rule: "schema_case_checker", pointer: storeSchema + "/properties/tags/items/properties/tags", suppress: true},
+		{name: "missing element", rule: "body_in_get_req", pointer: "/paths/~1pets/get/requestBody", suppress: true},
+		{name: "missing path", rule: "url_plural_checker", pointer: "/paths/~1cats"},
+		{name: "no pointer", rule: "url_plural_checker", pointer: ""},
+	}
+
+	s := newSuppressions(schema, fr.NewSourceMap(location, raw, refs))
+	// the component is inlined at many places but is a single suppression
+	if len(s.list) != 4 {
+		t.Fatalf("suppressions = %d, want 4", len(s.list))
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.suppress(tt.rule, tt.pointer, reportmanager.ReportDef{Message: tt.name}); got != tt.suppress {
+				t.Errorf("suppress() = %v, want %v", got, tt.suppress)
+			}
+		})
+	}
+
+	if unused := s.unused(); len(unused) != 0 {
+		t.Errorf("unused suppressions = %d, want 0", len(unused))
+	}
+}
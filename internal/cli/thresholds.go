@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/1-platform/api-catalog/internal/cli/reportmanager"
+)
+
+// exit codes of apic run thus CI pipelines can fail the build
+// when multiple thresholds are crossed the first one in this order is used
+const (
+	exitOK = 0
+	// invalid flags, config or schema and any other failure in running apic
+	exitError = 1
+	// more rules threw an exception than thresholds.max_failed_rules
+	exitRulesFailed = 2
+	// a category score is lower than thresholds.min_score
+	exitScoreBelowThreshold = 3
+	// more reports of thresholds.severity or above than thresholds.max_reports
+	exitTooManyReports = 4
+	// apic diff found breaking changes
+	exitBreakingChanges = 5
+)
+
+// thresholds for failing the run, unset ones are not checked
+type Thresholds struct {
+	// category -> minimum score
+	MinScore map[string]float32 `mapstructure:"min_score"`
+	// maximum number of reports across all rules
+	MaxReports *int `mapstructure:"max_reports"`
+	// only reports of this severity or above are counted in max_reports, defaults to all
+	Severity string `mapstructure:"severity"`
+	// maximum number of rules that can throw an exception
+	MaxFailedRules *int `mapstructure:"max_failed_rules"`
+}
+
+func (t *Thresholds) validate() error {
+	for category := range t.MinScore {
+		if category != "performance" && category != "security" && category != "quality" {
+			return fmt.Errorf("invalid category in thresholds.min_score: %s", category)
+		}
+	}
+	if t.Severity == "" {
+		t.Severity = reportmanager.SeverityHint
+	}
+	if reportmanager.SeverityRank(t.Severity) < 0 {
+		return fmt.Errorf("invalid thresholds.severity: %s", t.Severity)
+	}
+	return nil
+}
+
+// check the run result against thresholds
+// returns the exit code and the reason for each crossed threshold
+func (t *Thresholds) check(scores []reportmanager.Score, rm reportmanager.ReportManager, failedRules int) (int, []string) {
+	code := exitOK
+	var reasons []string
+	fail := func(c int, reason string) {
+		if code == exitOK || c < code {
+			code = c
+		}
+		reasons = append(reasons, reason)
+	}
+
+	if t.MaxFailedRules != nil && failedRules > *t.MaxFailedRules {
+		fail(exitRulesFailed, fmt.Sprintf("%d rules failed, allowed %d", failedRules, *t.MaxFailedRules))
+	}
+
+	categories := make([]string, 0, len(t.MinScore))
+	for category := range t.MinScore {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	for _, category := range categories {
+		for _, score := range scores {
+			if score.Category != category {
+				continue
+			}
+			// a rule dividing by zero items gives NaN, it can't be proven to meet the threshold
+			if math.IsNaN(float64(score.Value)) {
+				fail(exitScoreBelowThreshold, fmt.Sprintf("%s score is not a number, required %f", strings.Title(category), t.MinScore[category]))
+			} else if score.Value < t.MinScore[category] {
+				fail(exitScoreBelowThreshold, fmt.Sprintf("%s score %f is lower than %f", strings.Title(category), score.Value, t.MinScore[category]))
+			}
+		}
+	}
+
+	if t.MaxReports != nil {
+		reports := 0
+		for _, r := range rm {
+			for _, report := range r.Reports {
+				if reportmanager.SeverityRank(report.Severity) >= reportmanager.SeverityRank(t.Severity) {
+					reports++
+				}
+			}
+		}
+		if reports > *t.MaxReports {
+			fail(exitTooManyReports, fmt.Sprintf("%d reports of %s or above found, allowed %d", reports, t.Severity, *t.MaxReports))
+		}
+	}
+
+	return code, reasons
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"math"
+	"testing"
+
+	"github.com/1-platform/api-catalog/internal/cli/reportmanager"
+)
+
+func TestThresholdsValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		thresholds Thresholds
+		wantErr    bool
+	}{
+		{name: "empty", thresholds: Thresholds{}},
+		{name: "all categories", thresholds: Thresholds{MinScore: map[string]float32{"performance": 80, "security": 80, "quality": 80}}},
+		{name: "unknown category", thresholds: Thresholds{MinScore: map[string]float32{"speed": 80}}, wantErr: true},
+		{name: "severity", thresholds: Thresholds{Severity: reportmanager.SeverityWarning}},
+		{name: "unknown severity", thresholds: Thresholds{Severity: "critical"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.thresholds.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// reports of every severity are counted unless a severity is given
+	th := Thresholds{}
+	if err := th.validate(); err != nil || th.Severity != reportmanager.SeverityHint {
+		t.Errorf("validate() severity = %q, want %q", th.Severity, reportmanager.SeverityHint)
+	}
+}
+
+func TestThresholdsCheck(t *testing.T) {
+	zero, one, two := 0, 1, 2
+	rm := reportmanager.New()
+	rm.PushReport("url_case_checker", reportmanager.ReportDef{Message: "a", Severity: reportmanager.SeverityError})
+	rm.PushReport("url_case_checker", reportmanager.ReportDef{Message: "b", Severity: reportmanager.SeverityWarning})
+	rm.PushReport("status_code_check", reportmanager.ReportDef{Message: "c", Severity: reportmanager.SeverityInfo})
+	scores := []reportmanager.Score{{Category: "quality", Value: 70}, {Category: "performance", Value: 100}}
+
+	tests := []struct {
+		name        string
+		thresholds  Thresholds
+		scores      []reportmanager.Score
+		failedRules int
+		want        int
+		reasons     int
+	}{
+		{name: "no thresholds", failedRules: 3, want: exitOK},
+		{name: "score met", thresholds: Thresholds{MinScore: map[string]float32{"quality": 70}}, want: exitOK},
+		{name: "score below", thresholds: Thresholds{MinScore: map[string]float32{"quality": 80}}, want: exitScoreBelowThreshold, reasons: 1},
+		{name: "category without score", thresholds: Thresholds{MinScore: map[string]float32{"security": 80}}, want: exitOK},
+		{name: "score not a number", thresholds: Thresholds{MinScore: map[string]float32{"quality": 0}}, scores: []reportmanager.Score{{Category: "quality", Value: float32(math.NaN())}}, want: exitScoreBelowThreshold, reasons: 1},
+		{name: "reports within", thresholds: Thresholds{MaxReports: &two, Severity: reportmanager.SeverityWarning}, want: exitOK},
+		{name: "too many reports", thresholds: Thresholds{MaxReports: &two, Severity: reportmanager.SeverityHint}, want: exitTooManyReports, reasons: 1},
+		{name: "failed rules within", thresholds: Thresholds{MaxFailedRules: &one}, failedRules: 1, want: exitOK},
+		{name: "too many failed rules", thresholds: Thresholds{MaxFailedRules: &zero}, failedRules: 1, want: exitRulesFailed, reasons: 1},
+		{
+			name:        "lowest code of crossed thresholds",
+			thresholds:  Thresholds{MinScore: map[string]float32{"quality": 80}, MaxReports: &zero, Severity: reportmanager.SeverityHint, MaxFailedRules: &zero},
+			failedRules: 1,
+			want:        exitRulesFailed,
+			reasons:     3,
+		},
+		{
+			name:       "score and reports",
+			thresholds: Thresholds{MinScore: map[string]float32{"quality": 80}, MaxReports: &zero, Severity: reportmanager.SeverityHint},
+			want:       exitScoreBelowThreshold,
+			reasons:    2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.scores == nil {
+				tt.scores = scores
+			}
+			code, reasons := tt.thresholds.check(tt.scores, rm, tt.failedRules)
+			if code != tt.want || len(reasons) != tt.reasons {
+				t.Errorf("check() = %d with reasons %v, want %d with %d reasons", code, reasons, tt.want, tt.reasons)
+			}
+		})
+	}
+}
+
+func TestRunThresholds(t *testing.T) {
+	const spec = `openapi: 3.0.3
+info:
+  title: pets
+  version: 1.0.0
+paths:
+  /Pets:
+    get:
+      responses:
+        "200":
+          description: ok
+`
+	const config = `[thresholds]
+min_score = { quality = 100.0 }
+max_reports = 0
+severity = "warning"
+`
+	res := runTestRules(t, "openapi", "openapi.yaml", spec, config)
+	code, reasons := res.config.Thresholds.check(res.rm.GetTotalScore(), res.rm, len(res.ruleErrors))
+	if code != exitScoreBelowThreshold || len(reasons) != 2 {
+		t.Errorf("check() = %d with reasons %v, want %d for the score and the reports", code, reasons, exitScoreBelowThreshold)
+	}
+}
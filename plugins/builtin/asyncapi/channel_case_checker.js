@@ -0,0 +1,53 @@
+import { isCasing, jsonPointer } from "apic/strings";
+
+// for channel parameters like user/{userId}/signedup
+function isChannelParam(fragment) {
+  return fragment[0] === "{" && fragment[fragment.length - 1] === "}";
+}
+
+export default function (config, options) {
+  let numberOfResponses = 0;
+  let numbnerOfFalseResponses = 0;
+
+  const casing = options?.casing || "kebabcase";
+  const blackListChannels = options?.blacklist_channels || [];
+  // kafka topics are usually dot separated and mqtt topics slash separated
+  const separators = options?.separators || ["/", "."];
+
+  Object.keys(config.schema.channels || []).forEach((channel) => {
+    // next iteration
+    if (blackListChannels.includes(channel)) return;
+
+    const fragments = separators
+      .reduce((frags, sep) => frags.flatMap((f) => f.split(sep)), [channel])
+      .filter(Boolean);
+
+    for (let i = 0; i < fragments.length; i++) {
+      if (isChannelParam(fragments[i])) continue;
+
+      numberOfResponses++;
+      if (!isCasing(casing, fragments[i])) {
+        numbnerOfFalseResponses++;
+        const operations = ["publish", "subscribe"]
+          .filter((op) => config.schema.channels[channel][op])
+          .join(", ");
+
+        config.report({
+          message: `Channel is not ${casing}`,
+          path: channel,
+          method: operations,
+          pointer: jsonPointer("channels", channel),
+        });
+      }
+    }
+  });
+
+  // a schema without channels has nothing to penalize
+  const score =
+    numberOfResponses === 0
+      ? 100
+      : (Math.max(numberOfResponses - numbnerOfFalseResponses, 0) /
+          numberOfResponses) *
+        100;
+  config.setScore("quality", score);
+}
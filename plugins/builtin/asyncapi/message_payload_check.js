@@ -0,0 +1,48 @@
+import { jsonPointer } from "apic/strings";
+
+export default function (config) {
+  let numberOfResponses = 0;
+  let numbnerOfFalseResponses = 0;
+
+  Object.keys(config.schema.channels || []).forEach((channel) => {
+    ["publish", "subscribe"].forEach((op) => {
+      const message = config.schema.channels[channel][op]?.message;
+      if (!message) return;
+
+      // message refs are already resolved by apic
+      const messages = message.oneOf || [message];
+      messages.forEach((msg, i) => {
+        numberOfResponses++;
+        let pointer = jsonPointer("channels", channel, op, "message");
+        if (message.oneOf) pointer += `/oneOf/${i}`;
+        const name = msg.name || msg.title || "message";
+        if (!msg.payload) {
+          numbnerOfFalseResponses++;
+          config.report({
+            message: `Missing payload for ${name}`,
+            path: channel,
+            method: op,
+            pointer,
+          });
+        } else if (!msg.payload.type && !msg.payload.$ref) {
+          numbnerOfFalseResponses++;
+          config.report({
+            message: `Payload of ${name} has no type`,
+            path: channel,
+            method: op,
+            pointer: `${pointer}/payload`,
+          });
+        }
+      });
+    });
+  });
+
+  // channels without messages have nothing to penalize
+  const score =
+    numberOfResponses === 0
+      ? 100
+      : (Math.max(numberOfResponses - numbnerOfFalseResponses, 0) /
+          numberOfResponses) *
+        100;
+  config.setScore("quality", score);
+}
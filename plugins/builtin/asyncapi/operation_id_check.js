@@ -0,0 +1,43 @@
+import { isCasing, jsonPointer } from "apic/strings";
+
+export default function (config, options) {
+  let numberOfResponses = 0;
+  let numbnerOfFalseResponses = 0;
+
+  const casing = options?.casing || "camelcase";
+
+  Object.keys(config.schema.channels || []).forEach((channel) => {
+    ["publish", "subscribe"].forEach((op) => {
+      const operation = config.schema.channels[channel][op];
+      if (!operation) return;
+
+      numberOfResponses++;
+      if (!operation.operationId) {
+        numbnerOfFalseResponses++;
+        config.report({
+          message: "Missing operationId",
+          path: channel,
+          method: op,
+          pointer: jsonPointer("channels", channel, op),
+        });
+      } else if (!isCasing(casing, operation.operationId)) {
+        numbnerOfFalseResponses++;
+        config.report({
+          message: `Invalid casing for operationId ${operation.operationId}`,
+          path: channel,
+          method: op,
+          pointer: jsonPointer("channels", channel, op),
+        });
+      }
+    });
+  });
+
+  // channels without operations have nothing to penalize
+  const score =
+    numberOfResponses === 0
+      ? 100
+      : (Math.max(numberOfResponses - numbnerOfFalseResponses, 0) /
+          numberOfResponses) *
+        100;
+  config.setScore("quality", score);
+}
@@ -0,0 +1,37 @@
+export default function (config) {
+  let numberOfResponses = 0;
+  let numbnerOfFalseResponses = 0;
+
+  (config.schema.types || []).forEach((type, i) => {
+    const members = [
+      ...(type.fields || []).map((member, j) => [member, `fields/${j}`]),
+      ...(type.enumValues || []).map((member, j) => [
+        member,
+        `enumValues/${j}`,
+      ]),
+    ];
+
+    members.forEach(([member, pointer]) => {
+      if (!member.isDeprecated) return;
+
+      numberOfResponses++;
+      if (!member.deprecationReason) {
+        numbnerOfFalseResponses++;
+        config.report({
+          message: `${member.name} is deprecated without a reason`,
+          path: `${type.name}.${member.name}`,
+          pointer: `/types/${i}/${pointer}`,
+        });
+      }
+    });
+  });
+
+  // no deprecated fields means nothing to penalize
+  const score =
+    numberOfResponses === 0
+      ? 100
+      : (Math.max(numberOfResponses - numbnerOfFalseResponses, 0) /
+          numberOfResponses) *
+        100;
+  config.setScore("quality", score);
+}
@@ -0,0 +1,52 @@
+// operation root types are checked on field level
+function isRootType(schema, name) {
+  return (
+    name === schema.queryType ||
+    name === schema.mutationType ||
+    name === schema.subscriptionType
+  );
+}
+
+export default function (config, options) {
+  let numberOfResponses = 0;
+  let numbnerOfFalseResponses = 0;
+
+  const checkFields = options?.check_fields ?? true;
+
+  (config.schema.types || []).forEach((type, i) => {
+    if (!isRootType(config.schema, type.name)) {
+      numberOfResponses++;
+      if (!type.description) {
+        numbnerOfFalseResponses++;
+        config.report({
+          message: `Missing description for type ${type.name}`,
+          path: type.name,
+          pointer: `/types/${i}`,
+        });
+      }
+    }
+
+    if (!checkFields) return;
+
+    (type.fields || []).forEach((field, j) => {
+      numberOfResponses++;
+      if (!field.description) {
+        numbnerOfFalseResponses++;
+        config.report({
+          message: `Missing description for field ${field.name}`,
+          path: `${type.name}.${field.name}`,
+          pointer: `/types/${i}/fields/${j}`,
+        });
+      }
+    });
+  });
+
+  // a schema without types has nothing to penalize
+  const score =
+    numberOfResponses === 0
+      ? 100
+      : (Math.max(numberOfResponses - numbnerOfFalseResponses, 0) /
+          numberOfResponses) *
+        100;
+  config.setScore("quality", score);
+}
@@ -0,0 +1,44 @@
+import { isCasing } from "apic/strings";
+
+export default function (config, options) {
+  let numberOfResponses = 0;
+  let numbnerOfFalseResponses = 0;
+
+  const fieldCasing = options?.field_casing || "camelcase";
+  const argsCasing = options?.args_casing || "camelcase";
+
+  (config.schema.types || []).forEach((type, i) => {
+    (type.fields || []).forEach((field, j) => {
+      numberOfResponses++;
+      if (!isCasing(fieldCasing, field.name)) {
+        numbnerOfFalseResponses++;
+        config.report({
+          message: `Invalid casing for field ${field.name}`,
+          path: `${type.name}.${field.name}`,
+          pointer: `/types/${i}/fields/${j}`,
+        });
+      }
+
+      (field.args || []).forEach((arg, k) => {
+        numberOfResponses++;
+        if (!isCasing(argsCasing, arg.name)) {
+          numbnerOfFalseResponses++;
+          config.report({
+            message: `Invalid casing for argument ${arg.name}`,
+            path: `${type.name}.${field.name}`,
+            pointer: `/types/${i}/fields/${j}/args/${k}`,
+          });
+        }
+      });
+    });
+  });
+
+  // types without fields have nothing to penalize
+  const score =
+    numberOfResponses === 0
+      ? 100
+      : (Math.max(numberOfResponses - numbnerOfFalseResponses, 0) /
+          numberOfResponses) *
+        100;
+  config.setScore("quality", score);
+}
@@ -0,0 +1,33 @@
+import { isCasing } from "apic/strings";
+
+export default function (config, options) {
+  let numberOfResponses = 0;
+  let numbnerOfFalseResponses = 0;
+
+  const casing = options?.casing || "pascalcase";
+  const blackListTypes = options?.blacklist_types || [];
+
+  (config.schema.types || []).forEach((type, i) => {
+    // next iteration
+    if (blackListTypes.includes(type.name)) return;
+
+    numberOfResponses++;
+    if (!isCasing(casing, type.name)) {
+      numbnerOfFalseResponses++;
+      config.report({
+        message: `Type name is not ${casing}`,
+        path: type.name,
+        pointer: `/types/${i}`,
+      });
+    }
+  });
+
+  // a schema without types has nothing to penalize
+  const score =
+    numberOfResponses === 0
+      ? 100
+      : (Math.max(numberOfResponses - numbnerOfFalseResponses, 0) /
+          numberOfResponses) *
+        100;
+  config.setScore("quality", score);
+}
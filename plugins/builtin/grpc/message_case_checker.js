@@ -0,0 +1,42 @@
+import { isCasing } from "apic/strings";
+
+export default function (config, options) {
+  let numberOfResponses = 0;
+  let numbnerOfFalseResponses = 0;
+
+  const messageCasing = options?.message_casing || "pascalcase";
+  const fieldCasing = options?.field_casing || "snakecase";
+
+  (config.schema.messages || []).forEach((message, i) => {
+    numberOfResponses++;
+    if (!isCasing(messageCasing, message.name)) {
+      numbnerOfFalseResponses++;
+      config.report({
+        message: `Message name is not ${messageCasing}`,
+        path: message.fullName,
+        pointer: `/messages/${i}`,
+      });
+    }
+
+    (message.fields || []).forEach((field, j) => {
+      numberOfResponses++;
+      if (!isCasing(fieldCasing, field.name)) {
+        numbnerOfFalseResponses++;
+        config.report({
+          message: `Invalid casing for field ${field.name}`,
+          path: message.fullName,
+          pointer: `/messages/${i}/fields/${j}`,
+        });
+      }
+    });
+  });
+
+  // a schema without messages has nothing to penalize
+  const score =
+    numberOfResponses === 0
+      ? 100
+      : (Math.max(numberOfResponses - numbnerOfFalseResponses, 0) /
+          numberOfResponses) *
+        100;
+  config.setScore("quality", score);
+}
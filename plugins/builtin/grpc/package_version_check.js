@@ -0,0 +1,39 @@
+// versions like v1, v2beta1, v1alpha
+const versionRegex = /^v\d+((alpha|beta)\d*)?$/;
+
+export default function (config) {
+  let numberOfResponses = 0;
+  let numbnerOfFalseResponses = 0;
+
+  (config.schema.files || []).forEach((file, i) => {
+    numberOfResponses++;
+    if (!file.package) {
+      numbnerOfFalseResponses++;
+      config.report({
+        message: "File has no package",
+        path: file.name,
+        pointer: `/files/${i}`,
+      });
+      return;
+    }
+
+    const fragments = file.package.split(".");
+    if (!versionRegex.test(fragments[fragments.length - 1])) {
+      numbnerOfFalseResponses++;
+      config.report({
+        message: `Package ${file.package} is not versioned`,
+        path: file.name,
+        pointer: `/files/${i}`,
+      });
+    }
+  });
+
+  // a schema without files has nothing to penalize
+  const score =
+    numberOfResponses === 0
+      ? 100
+      : (Math.max(numberOfResponses - numbnerOfFalseResponses, 0) /
+          numberOfResponses) *
+        100;
+  config.setScore("quality", score);
+}
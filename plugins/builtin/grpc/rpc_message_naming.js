@@ -0,0 +1,44 @@
+function shortName(fullName) {
+  const fragments = fullName.split(".");
+  return fragments[fragments.length - 1];
+}
+
+export default function (config, options) {
+  let numberOfResponses = 0;
+  let numbnerOfFalseResponses = 0;
+
+  // like google.protobuf.Empty which are shared across rpcs
+  const allowedTypes = options?.allowed_types || [];
+
+  (config.schema.services || []).forEach((service, i) => {
+    (service.methods || []).forEach((rpc, j) => {
+      const expected = [
+        [rpc.inputType, `${rpc.name}Request`],
+        [rpc.outputType, `${rpc.name}Response`],
+      ];
+
+      expected.forEach(([type, name]) => {
+        if (allowedTypes.includes(type)) return;
+
+        numberOfResponses++;
+        if (shortName(type) !== name) {
+          numbnerOfFalseResponses++;
+          config.report({
+            message: `${type} should be named ${name}`,
+            path: rpc.path,
+            pointer: `/services/${i}/methods/${j}`,
+          });
+        }
+      });
+    });
+  });
+
+  // rpcs with only allowed types have nothing to penalize
+  const score =
+    numberOfResponses === 0
+      ? 100
+      : (Math.max(numberOfResponses - numbnerOfFalseResponses, 0) /
+          numberOfResponses) *
+        100;
+  config.setScore("quality", score);
+}
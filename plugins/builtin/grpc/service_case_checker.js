@@ -0,0 +1,42 @@
+import { isCasing } from "apic/strings";
+
+export default function (config, options) {
+  let numberOfResponses = 0;
+  let numbnerOfFalseResponses = 0;
+
+  const serviceCasing = options?.service_casing || "pascalcase";
+  const rpcCasing = options?.rpc_casing || "pascalcase";
+
+  (config.schema.services || []).forEach((service, i) => {
+    numberOfResponses++;
+    if (!isCasing(serviceCasing, service.name)) {
+      numbnerOfFalseResponses++;
+      config.report({
+        message: `Service name is not ${serviceCasing}`,
+        path: service.fullName,
+        pointer: `/services/${i}`,
+      });
+    }
+
+    (service.methods || []).forEach((rpc, j) => {
+      numberOfResponses++;
+      if (!isCasing(rpcCasing, rpc.name)) {
+        numbnerOfFalseResponses++;
+        config.report({
+          message: `RPC name is not ${rpcCasing}`,
+          path: rpc.path,
+          pointer: `/services/${i}/methods/${j}`,
+        });
+      }
+    });
+  });
+
+  // a schema without services has nothing to penalize
+  const score =
+    numberOfResponses === 0
+      ? 100
+      : (Math.max(numberOfResponses - numbnerOfFalseResponses, 0) /
+          numberOfResponses) *
+        100;
+  config.setScore("quality", score);
+}
@@ -2,34 +2,22 @@ export default function (config) {
   let numberOfResponses = 0;
   let numbnerOfFalseResponses = 0;
 
-  Object.keys(config.schema.paths || []).forEach((path) => {
-    Object.keys(config.schema.paths[path] || []).forEach((method) => {
+  (config.operations || []).forEach(
+    ({ path, method, requestBody, pointer }) => {
       if (method === "get") {
         numberOfResponses++;
-        if (config.schema.paths[path][method].hasOwnProperty("requestBody")) {
+        if (requestBody) {
           numbnerOfFalseResponses++;
           config.report({
             message: "Request body in GET request",
             path: path,
             method: method,
+            pointer: pointer,
           });
         }
-
-        (config.schema.paths[path][method].parameters || []).forEach(
-          (param) => {
-            if (param.in === "body") {
-              numbnerOfFalseResponses++;
-              config.report({
-                message: "Request body in GET request",
-                path: path,
-                method: method,
-              });
-            }
-          }
-        );
       }
-    });
-  });
+    }
+  );
   // if number goes to negative
   const score =
     (Math.max(numberOfResponses - numbnerOfFalseResponses, 0) /
@@ -1,4 +1,4 @@
-import { isCasing } from "apic/strings";
+import { isCasing, jsonPointer } from "apic/strings";
 
 export default function (config, options = {}) {
   let numberOfResponses = 0;
@@ -6,23 +6,22 @@ export default function (config, options = {}) {
   const reqBodyCasing = options?.req_body_casing || "camelcase";
   const paramsCasing = options?.params_casing || "camelcase";
 
-  Object.keys(config.schema.paths || []).forEach((path) => {
-    Object.keys(config.schema.paths[path] || []).forEach((method) => {
-      (config.schema.paths[path][method].parameters || []).forEach((param) => {
-        numberOfResponses++;
-        if (!isCasing(paramsCasing, param.name)) {
-          numbnerOfFalseResponses++;
-          config.report({
-            message: `Invalid casing for ${param.name} of ${param.in}`,
-            path: path,
-            method: method,
-          });
-        }
-      });
+  (config.operations || []).forEach(({ path, method, parameters }) => {
+    parameters.forEach((param) => {
+      numberOfResponses++;
+      if (!isCasing(paramsCasing, param.name)) {
+        numbnerOfFalseResponses++;
+        config.report({
+          message: `Invalid casing for ${param.name} of ${param.in}`,
+          path: path,
+          method: method,
+          pointer: param.pointer,
+        });
+      }
     });
   });
 
-  Object.keys(config.schema.components.schemas || []).forEach((schema) => {
+  Object.keys(config.schema.components?.schemas || []).forEach((schema) => {
     Object.keys(
       config.schema.components.schemas[schema].properties || []
     ).forEach((property) => {
@@ -31,8 +30,13 @@ export default function (config, options = {}) {
         numbnerOfFalseResponses++;
         config.report({
           message: `Invalid casing for ${property} of schema ${schema}`,
-          path: "Nil",
-          method: "Nil",
+          pointer: jsonPointer(
+            "components",
+            "schemas",
+            schema,
+            "properties",
+            property
+          ),
         });
       }
     });
@@ -1,3 +1,5 @@
+import { jsonPointer } from "apic/strings";
+
 // By this spec: https://perishablepress.com/stop-using-unsafe-characters-in-urls/
 const unsafeURLRegex = /^[a-zA-Z0-9{}\/~_-]*$/;
 
@@ -18,6 +20,7 @@ export default function (config) {
         message: `URL contains unsafe character`,
         path: path,
         method: methods,
+        pointer: jsonPointer("paths", path),
       });
     }
   });
@@ -1,4 +1,4 @@
-import { isCasing } from "apic/strings";
+import { isCasing, jsonPointer } from "apic/strings";
 
 // for dynamic parameters like /pets/{something}
 function isDynamicParams(path) {
@@ -53,6 +53,7 @@ export default function (config, options) {
           message: `URL is not ${casing}`,
           path: strippedPath,
           method: methods,
+          pointer: jsonPointer("paths", path),
         });
       }
     }
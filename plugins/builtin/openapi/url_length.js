@@ -1,3 +1,5 @@
+import { jsonPointer } from "apic/strings";
+
 // for giving a score to {something} kinda paths in openapi
 function isDynamicPathFragment(path) {
   return path[0] === "{" && path[path.length - 1] === "}";
@@ -39,6 +41,7 @@ export default function (config, options) {
         message: `URL is too big, Resources: ${resources} Length: ${resourceLength} Weight: ${dynamicPathWeight}`,
         path: path,
         method: methods,
+        pointer: jsonPointer("paths", path),
       });
     }
   });
@@ -1,4 +1,4 @@
-import { isPlural, isSingular } from "apic/strings";
+import { isPlural, isSingular, jsonPointer } from "apic/strings";
 
 // for dynamic parameters like /pets/{something}
 function isDynamicParams(path) {
@@ -55,6 +55,7 @@ export default function (config, options) {
           message: `URL is is not ${type}`,
           path: path,
           method: methods,
+          pointer: jsonPointer("paths", path),
         });
       }
     }
@@ -1,3 +1,5 @@
+import { jsonPointer } from "apic/strings";
+
 // Kudos: https://github.com/aceakash/string-similarity
 function compareTwoStrings(first, second) {
   first = first.replace(/\s+/g, "");
@@ -44,7 +46,8 @@ export default function (config, options) {
   const blackListPaths = options?.blacklist_paths || [];
   const baseURLs = options?.base_urls || [];
 
-  const paths = Object.keys(config.schema.paths || []);
+  // sorted thus the same pair is reported the same way in every run
+  const paths = Object.keys(config.schema.paths || []).sort();
   for (let i = 0; i < paths.length; i++) {
     // skip blacklisted paths
     const pathA = stripOfBaseURL(paths[i], baseURLs);
@@ -68,6 +71,7 @@ export default function (config, options) {
             message: `URL ${paths[i]} similiar to ${paths[j]}, similiarity: ${similiarity}`,
             path: paths[i],
             method: methods,
+            pointer: jsonPointer("paths", paths[i]),
           });
         }
       }